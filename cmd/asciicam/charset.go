@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/muesli/asciicam/internal/ascii"
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+const (
+	// defaultRampChars are the candidates for generated ramps: printable ASCII.
+	defaultRampChars = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+	// defaultRampLevels is the length of generated ramps.
+	defaultRampLevels = 16
+)
+
+// runCharset implements the charset subcommand, which builds a ramp from the
+// glyphs of a font, ordered and spaced by how much of their cell they cover.
+// The ramp is printed ready to be passed to -charset.
+func runCharset(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("charset", flag.ContinueOnError)
+	fontPath := fs.String("font", "", "TrueType or OpenType font the terminal renders with (required)")
+	chars := fs.String("chars", defaultRampChars, "candidate characters for the ramp")
+	levels := fs.Int("levels", defaultRampLevels, "number of characters in the ramp")
+	verbose := fs.Bool("v", false, "print the coverage of every candidate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *fontPath == "" {
+		return fmt.Errorf("%w: missing -font", errors.ErrInvalidConfig)
+	}
+
+	data, err := os.ReadFile(*fontPath)
+	if err != nil {
+		return fmt.Errorf("error reading font: %w", err)
+	}
+	glyphs, err := ascii.MeasureGlyphs(data, *chars)
+	if err != nil {
+		return err
+	}
+	if *verbose {
+		printCoverage(w, glyphs)
+	}
+
+	ramp, err := ascii.RampFromGlyphs(glyphs, *levels)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, ascii.CustomCharsetPrefix+ramp)
+	return nil
+}
+
+// printCoverage writes the coverage of each glyph to w, one per line.
+func printCoverage(w io.Writer, glyphs []ascii.GlyphCoverage) {
+	for _, g := range glyphs {
+		fmt.Fprintf(w, "%q %5.1f%% %s\n", g.Rune, g.Coverage*100, strings.Repeat("#", int(g.Coverage*50+0.5)))
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/image/font/gofont/gomono"
+
+	"github.com/muesli/asciicam/internal/ascii"
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestRunCharset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mono.ttf")
+	if err := os.WriteFile(path, gomono.TTF, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := runCharset([]string{"--font", path, "--chars", " .:-=+*#%@", "--levels", "6"}, &buf); err != nil {
+		t.Fatalf("runCharset failed: %v", err)
+	}
+
+	out := strings.TrimSpace(buf.String())
+	ramp, err := ascii.ParseCharset(out)
+	if err != nil {
+		t.Fatalf("Expected output usable as -charset, got %q: %v", out, err)
+	}
+	if n := utf8.RuneCountInString(ramp); n != 6 {
+		t.Errorf("Expected 6 glyphs, got %d in %q", n, ramp)
+	}
+	if !strings.HasPrefix(ramp, " ") || !strings.HasSuffix(ramp, "@") {
+		t.Errorf("Expected ramp from space to @, got %q", ramp)
+	}
+}
+
+func TestRunCharset_MissingFont(t *testing.T) {
+	var buf bytes.Buffer
+	if err := runCharset(nil, &buf); !stderrors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig without -font, got %v", err)
+	}
+}
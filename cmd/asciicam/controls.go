@@ -0,0 +1,160 @@
+package main
+
+import (
+	"time"
+
+	"github.com/muesli/asciicam/internal/camera"
+	"github.com/muesli/asciicam/internal/greenscreen"
+	"github.com/muesli/asciicam/internal/input"
+)
+
+const (
+	// seekStep is how far the arrow keys seek in video playback.
+	seekStep = 5 * time.Second
+	// thresholdStep is how much +/- change the greenscreen threshold.
+	thresholdStep = 0.01
+	// panStep is how far h/j/k/l pan, as a fraction of the visible region.
+	panStep = 0.1
+	// zoomStep is the factor i/o change the digital zoom by.
+	zoomStep = 1.25
+	// brightnessStep is how much b/B change the brightness.
+	brightnessStep = 0.05
+	// contrastStep is how much t/T change the contrast.
+	contrastStep = 0.1
+	// gammaStep is how much g/G change the gamma.
+	gammaStep = 0.1
+	// saturationStep is how much s/S change the saturation.
+	saturationStep = 0.1
+	// hueStep is how many degrees u/U turn the hue.
+	hueStep = 10
+)
+
+// autoLevelsCycle is the order the a key cycles through the auto levels modes.
+var autoLevelsCycle = []camera.AutoLevels{camera.AutoLevelsNone, camera.AutoLevelsStretch, camera.AutoLevelsEqualize}
+
+// controls maps key presses to actions on the running pipeline.
+type controls struct {
+	keys        <-chan input.Key
+	source      camera.FrameSource
+	greenscreen *greenscreen.Processor
+	viewport    *camera.Viewport
+	adjuster    *camera.Adjuster
+	configured  camera.Adjustments // adjustments restored by r
+}
+
+// handleKeys applies all pending key presses without blocking.
+// It reports whether the user asked to quit.
+func (c *controls) handleKeys() bool {
+	for {
+		select {
+		case key := <-c.keys:
+			if c.handleKey(key) {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+// handleKey applies a single key press. It reports whether the user asked to quit.
+func (c *controls) handleKey(key input.Key) bool {
+	switch key {
+	case 'q', 'Q', input.KeyEscape:
+		return true
+	case ' ', 'p':
+		if p, ok := c.source.(camera.Pauser); ok {
+			p.TogglePause()
+		}
+	case input.KeyLeft:
+		if s, ok := c.source.(camera.Seeker); ok {
+			_ = s.Seek(-seekStep)
+		}
+	case input.KeyRight:
+		if s, ok := c.source.(camera.Seeker); ok {
+			_ = s.Seek(seekStep)
+		}
+	case '+', '=':
+		if c.greenscreen != nil {
+			c.greenscreen.SetThreshold(c.greenscreen.GetThreshold() + thresholdStep)
+		}
+	case '-':
+		if c.greenscreen != nil && c.greenscreen.GetThreshold() > thresholdStep {
+			c.greenscreen.SetThreshold(c.greenscreen.GetThreshold() - thresholdStep)
+		}
+	case 'h', 'j', 'k', 'l', 'i', 'o', 'c':
+		if c.viewport != nil {
+			c.handleViewportKey(key)
+		}
+	case 'b', 'B', 't', 'T', 'g', 'G', 's', 'S', 'u', 'U', 'a', 'r':
+		if c.adjuster != nil {
+			c.handleAdjustmentKey(key)
+		}
+	}
+
+	return false
+}
+
+// handleViewportKey pans and zooms the viewport.
+func (c *controls) handleViewportKey(key input.Key) {
+	switch key {
+	case 'h':
+		c.viewport.Pan(-panStep, 0)
+	case 'l':
+		c.viewport.Pan(panStep, 0)
+	case 'k':
+		c.viewport.Pan(0, -panStep)
+	case 'j':
+		c.viewport.Pan(0, panStep)
+	case 'i':
+		c.viewport.Zoom(zoomStep)
+	case 'o':
+		c.viewport.Zoom(1 / zoomStep)
+	case 'c':
+		c.viewport.Reset()
+	}
+}
+
+// handleAdjustmentKey changes the image adjustments. Lowercase keys lower a
+// setting, uppercase keys raise it.
+func (c *controls) handleAdjustmentKey(key input.Key) {
+	adj := c.adjuster.Adjustments()
+	switch key {
+	case 'b':
+		adj.Brightness -= brightnessStep
+	case 'B':
+		adj.Brightness += brightnessStep
+	case 't':
+		adj.Contrast -= contrastStep
+	case 'T':
+		adj.Contrast += contrastStep
+	case 'g':
+		adj.Gamma -= gammaStep
+	case 'G':
+		adj.Gamma += gammaStep
+	case 's':
+		adj.Saturation -= saturationStep
+	case 'S':
+		adj.Saturation += saturationStep
+	case 'u':
+		adj.Hue -= hueStep
+	case 'U':
+		adj.Hue += hueStep
+	case 'a':
+		adj.AutoLevels = nextAutoLevels(adj.AutoLevels)
+	case 'r':
+		adj = c.configured
+	}
+	c.adjuster.Set(adj)
+}
+
+// nextAutoLevels returns the auto levels mode following mode in
+// autoLevelsCycle.
+func nextAutoLevels(mode camera.AutoLevels) camera.AutoLevels {
+	for i, m := range autoLevelsCycle {
+		if m == mode {
+			return autoLevelsCycle[(i+1)%len(autoLevelsCycle)]
+		}
+	}
+	return autoLevelsCycle[0]
+}
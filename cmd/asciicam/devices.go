@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/asciicam/internal/camera"
+)
+
+// runDevices implements the devices subcommand, which lists the available
+// capture devices.
+func runDevices(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("devices", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print devices as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	devices, err := camera.ListDevices()
+	if err != nil {
+		return fmt.Errorf("error listing devices: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(devices)
+	}
+
+	printDevices(w, devices)
+	return nil
+}
+
+// printDevices writes a human readable list of devices to w.
+func printDevices(w io.Writer, devices []camera.Device) {
+	if len(devices) == 0 {
+		fmt.Fprintln(w, "No capture devices found.")
+		return
+	}
+
+	for i, d := range devices {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		if d.Error != "" {
+			fmt.Fprintf(w, "%d: %s (error: %s)\n", d.ID, d.Path, d.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%d: %s %s (%s, %s)\n", d.ID, d.Path, d.Name, d.Driver, d.Bus)
+
+		for _, f := range d.Formats {
+			fmt.Fprintf(w, "   %-4s %s\n", f.PixelFormat, f.Description)
+			for _, s := range f.Sizes {
+				size := fmt.Sprintf("%dx%d", s.Width, s.Height)
+				if len(s.FrameRates) == 0 {
+					fmt.Fprintf(w, "        %s\n", size)
+					continue
+				}
+				fmt.Fprintf(w, "        %-10s %s fps\n", size, formatRates(s.FrameRates))
+			}
+		}
+	}
+}
+
+// formatRates formats frame rates as a comma separated list, without
+// trailing zeros.
+func formatRates(rates []float64) string {
+	s := make([]string, len(rates))
+	for i, r := range rates {
+		s[i] = strconv.FormatFloat(r, 'f', -1, 64)
+		if len(s[i]) > 5 {
+			s[i] = strconv.FormatFloat(r, 'f', 2, 64)
+		}
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/asciicam/internal/camera"
+)
+
+func TestPrintDevices(t *testing.T) {
+	devices := []camera.Device{
+		{
+			ID:     0,
+			Path:   "/dev/video0",
+			Name:   "Integrated Camera",
+			Driver: "uvcvideo",
+			Bus:    "usb-0000:00:14.0-6",
+			Formats: []camera.DeviceFormat{
+				{
+					PixelFormat: "MJPG",
+					Description: "Motion-JPEG",
+					Sizes: []camera.FrameSize{
+						{Width: 1280, Height: 720, FrameRates: []float64{30, 15}},
+						{Width: 640, Height: 480, FrameRates: []float64{30000.0 / 1001}},
+					},
+				},
+			},
+		},
+		{ID: 2, Path: "/dev/video2", Error: "permission denied"},
+	}
+
+	var buf bytes.Buffer
+	printDevices(&buf, devices)
+
+	want := `0: /dev/video0 Integrated Camera (uvcvideo, usb-0000:00:14.0-6)
+   MJPG Motion-JPEG
+        1280x720   30, 15 fps
+        640x480    29.97 fps
+
+2: /dev/video2 (error: permission denied)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintDevices_None(t *testing.T) {
+	var buf bytes.Buffer
+	printDevices(&buf, nil)
+
+	if !strings.Contains(buf.String(), "No capture devices") {
+		t.Errorf("Expected a message about missing devices, got %q", buf.String())
+	}
+}
+
+func TestRunDevices_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := runDevices([]string{"--json"}, &buf); err != nil {
+		t.Skipf("device listing unavailable: %v", err)
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(buf.String()), "[") {
+		t.Errorf("Expected a JSON array, got %q", buf.String())
+	}
+}
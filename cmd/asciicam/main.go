@@ -4,16 +4,22 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/muesli/asciicam/internal/ascii"
 	"github.com/muesli/asciicam/internal/camera"
 	"github.com/muesli/asciicam/internal/config"
+	"github.com/muesli/asciicam/internal/errors"
 	"github.com/muesli/asciicam/internal/greenscreen"
+	"github.com/muesli/asciicam/internal/input"
+	"github.com/muesli/asciicam/internal/screen"
 	"github.com/muesli/termenv"
+	"golang.org/x/term"
 )
 
 func main() {
@@ -27,12 +33,142 @@ func main() {
 		cancel()
 	}()
 
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "devices" {
+		if err := runDevices(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "charset" {
+		if err := runCharset(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+const (
+	// testPatternFPS is the frame rate synthetic test patterns are generated at.
+	testPatternFPS = 30
+	// captureBufferSize is the number of frames buffered by the capture worker.
+	captureBufferSize = 3
+	// framePoolSize is the number of unused camera frames kept for reuse.
+	framePoolSize = 4
+)
+
+// openSource opens the frame source selected by the configuration.
+func openSource(cfg *config.Config) (camera.FrameSource, error) {
+	camWidth, camHeight := cfg.GetCameraDimensions()
+
+	if cfg.Input == config.StdinInput {
+		return camera.NewRawStream(cfg.Stdin(), "stdin", camWidth, camHeight)
+	}
+
+	if pattern, ok := strings.CutPrefix(cfg.Input, config.TestPatternPrefix); ok {
+		return camera.NewTestPattern(pattern, camWidth, camHeight, testPatternFPS)
+	}
+
+	if cfg.Input != "" {
+		if camera.IsImageInput(cfg.Input) {
+			return camera.NewImageSequence(cfg.Input, cfg.Interval, cfg.Loop)
+		}
+		return camera.NewVideoFile(cfg.Input, cfg.Loop)
+	}
+
+	return openCamera(cfg, cfg.DeviceID)
+}
+
+// openCamera opens a camera with the backend selected by the configuration.
+func openCamera(cfg *config.Config, deviceID int) (camera.FrameSource, error) {
+	camWidth, camHeight := cfg.GetCameraDimensions()
+
+	switch cfg.Backend {
+	case config.BackendV4L2:
+		return camera.NewV4L2Capture(deviceID, camWidth, camHeight)
+	case config.BackendOpenCV:
+		return camera.NewCapture(deviceID, camWidth, camHeight)
+	}
+
+	if !camera.OpenCVAvailable {
+		return camera.NewV4L2Capture(deviceID, camWidth, camHeight)
+	}
+	return camera.NewCapture(deviceID, camWidth, camHeight)
+}
+
+// setupCamera prepares a freshly opened camera: it applies the requested
+// properties, warning on w about ignored ones, takes frames from pool and
+// shrinks them to the size kept by sizer before converting them.
+func setupCamera(w io.Writer, source camera.FrameSource, props camera.Properties, pool *camera.FramePool, sizer *frameSizer) {
+	applyProperties(w, source, props)
+
+	if p, ok := source.(camera.FramePooler); ok {
+		p.SetFramePool(pool)
+	}
+	if d, ok := source.(camera.Downscaler); ok {
+		sizer.attach(d)
+	}
+}
+
+// reopenCamera returns a function that reopens the configured camera after
+// it was lost and sets it up again. Where possible the camera is found again
+// by its stable path, as it may come back with another ID.
+func reopenCamera(cfg *config.Config, props camera.Properties, pool *camera.FramePool, sizer *frameSizer) func() (camera.FrameSource, error) {
+	stablePath, stable := camera.StableDevicePath(cfg.DeviceID)
+
+	return func() (camera.FrameSource, error) {
+		deviceID := cfg.DeviceID
+		if stable {
+			id, err := camera.DeviceIDFromPath(stablePath)
+			if err != nil {
+				return nil, err
+			}
+			deviceID = id
+		}
+
+		source, err := openCamera(cfg, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		setupCamera(io.Discard, source, props, pool, sizer)
+		return source, nil
+	}
+}
+
+// orientation returns the mirror, flip and rotate settings of cfg.
+func orientation(cfg *config.Config) camera.Orientation {
+	return camera.Orientation{Mirror: cfg.Mirror, Flip: cfg.Flip, Rotate: cfg.Rotate}
+}
+
+// adjustments returns the image adjustments of cfg.
+func adjustments(cfg *config.Config) camera.Adjustments {
+	return camera.Adjustments{
+		Brightness: cfg.AdjustBrightness,
+		Contrast:   cfg.AdjustContrast,
+		Gamma:      cfg.AdjustGamma,
+		Saturation: cfg.AdjustSaturation,
+		Hue:        cfg.AdjustHue,
+		AutoLevels: camera.AutoLevels(cfg.AutoLevels),
+	}
+}
+
+// orientFrame mirrors, flips and rotates img as configured. When that takes a
+// new frame from pool, img is handed back to it.
+func orientFrame(orient camera.Orientation, pool *camera.FramePool, img image.Image) image.Image {
+	oriented := orient.Apply(img, pool)
+	if oriented != img {
+		pool.Put(img)
+	}
+	return oriented
+}
+
 func run(ctx context.Context) error {
 	// Initialize configuration
 	cfg := config.NewConfig()
@@ -40,19 +176,46 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("error parsing flags: %w", err)
 	}
 
-	// Initialize camera capture
-	camWidth, camHeight := cfg.GetCameraDimensions()
-	capture, err := camera.NewCapture(cfg.DeviceID, camWidth, camHeight)
+	// Initialize frame source
+	source, err := openSource(cfg)
 	if err != nil {
 		return fmt.Errorf("error initializing camera: %w", err)
 	}
-	defer capture.Close()
+
+	// Set up the camera, reconnect it when it goes away and capture on a
+	// separate goroutine so slow terminals do not throttle the camera
+	var async *camera.AsyncSource
+	var pool *camera.FramePool
+	sizer := &frameSizer{}
+	sizer.setSize(maxFrameSize(cfg))
+	if cfg.Input == "" {
+		props := cameraProperties(cfg.Properties)
+		pool = camera.NewFramePool(framePoolSize)
+		setupCamera(os.Stderr, source, props, pool, sizer)
+		source = camera.NewReconnectingSource(source, cfg.DeviceID, reopenCamera(cfg, props, pool, sizer))
+		async = camera.NewAsyncSource(source, captureBufferSize)
+		source = async
+	}
+	defer source.Close()
 
 	// Initialize ASCII converter
 	converter := ascii.NewConverter()
 	if cfg.ParsedColor != nil {
 		converter.SetGlobalColor(cfg.ParsedColor)
 	}
+	ramp, err := ascii.ParseCharset(cfg.Charset)
+	if err != nil {
+		return fmt.Errorf("error selecting charset: %w", err)
+	}
+	if err := converter.SetCharset(ramp); err != nil {
+		return fmt.Errorf("error selecting charset: %w", err)
+	}
+	converter.SetInvert(cfg.InvertRamp)
+	if cfg.Edges {
+		converter.SetEdges(cfg.EdgeThreshold)
+	}
+	converter.SetBrailleDither(cfg.BrailleDither)
+	converter.SetDither(ascii.DitherMode(cfg.Dither))
 
 	// Initialize greenscreen processor if needed
 	var gsProcessor *greenscreen.Processor
@@ -66,18 +229,58 @@ func run(ctx context.Context) error {
 		}
 	}
 
-	// Get display dimensions
-	termWidth, termHeight := cfg.GetDisplayDimensions()
-	scaledWidth, scaledHeight := cfg.GetScaledDimensions()
+	orient := orientation(cfg)
+	configured := adjustments(cfg)
+	adjuster := camera.NewAdjuster(configured)
+
+	// Digital zoom, optionally following a face
+	viewport := newViewport(cfg)
+	faces, err := newFaceTracker(cfg, viewport)
+	if err != nil {
+		return fmt.Errorf("error loading face detector: %w", err)
+	}
+	defer faces.Close()
 
 	// Set up terminal
 	output := termenv.NewOutput(os.Stdout)
 	p := output.ColorProfile()
+
+	// Render a single frame without taking over the terminal
+	if cfg.Once {
+		img, err := source.ReadFrameWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading frame: %w", err)
+		}
+		img = orientFrame(orient, pool, img)
+		faces.track(img)
+		fmt.Print(renderFrame(cfg, converter, gsProcessor, adjuster, p, viewport.Crop(img)).String())
+		return nil
+	}
+
 	output.HideCursor()
 	defer output.ShowCursor()
 	output.AltScreen()
 	defer output.ExitAltScreen()
 
+	// Interactive controls, if stdin is a terminal
+	ctrl := &controls{
+		source:      source,
+		greenscreen: gsProcessor,
+		viewport:    viewport,
+		adjuster:    adjuster,
+		configured:  configured,
+	}
+	if kb, err := input.NewKeyboard(os.Stdin); err == nil {
+		defer kb.Close()
+		ctrl.keys = kb.Keys()
+	}
+
+	// Follow the terminal size
+	resized := watchResize(ctx)
+
+	// Redraw only what changed, unless every frame is drawn in full
+	renderer := newRenderer(cfg)
+
 	// Clear screen at the beginning
 	fmt.Print("\033[2J") // Clear entire screen
 	fmt.Print("\033[H")  // Move cursor to the top-left corner
@@ -94,14 +297,39 @@ func run(ctx context.Context) error {
 			return nil
 		}
 
-		// Read frame from camera
-		img, err := capture.ReadFrameWithContext(ctx)
+		if quit := ctrl.handleKeys(); quit {
+			return nil
+		}
+
+		select {
+		case <-resized:
+			if err := resize(cfg, gsProcessor, sizer, renderer); err != nil {
+				return err
+			}
+		default:
+		}
+
+		// Read frame from source
+		img, err := source.ReadFrameWithContext(ctx)
+		if errors.IsEndOfStream(err) {
+			return nil
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading frame: %v\n", err)
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// Show the problem in the viewport, stderr would corrupt the screen
+			fmt.Print("\033[H\033[J")
+			fmt.Print(statusMessage(err))
+			renderer.Invalidate()
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
 
+		// Mirror, flip and rotate before anything else sees the frame
+		img = orientFrame(orient, pool, img)
+
 		// Handle background sample generation
 		if cfg.GenerateSamples {
 			if err := gsProcessor.GenerateSamples(img, frameCount); err != nil {
@@ -115,30 +343,14 @@ func run(ctx context.Context) error {
 			continue
 		}
 
-		// Resize image based on calculated dimensions
-		resizedImg := capture.ResizeImage(img, scaledWidth, scaledHeight)
-
-		// Apply greenscreen effect if enabled
-		if cfg.UseGreenscreen && gsProcessor != nil {
-			if rgbaImg, ok := resizedImg.(*image.RGBA); ok {
-				gsProcessor.Apply(rgbaImg)
-				resizedImg = rgbaImg
-			}
-		}
-
 		// Convert to ASCII/ANSI
 		now := time.Now()
-		var output string
-		if cfg.ANSI {
-			output = converter.ImageToANSI(p, resizedImg)
-		} else {
-			output = converter.ImageToASCII(termWidth, termHeight, p, resizedImg)
-		}
+		faces.track(img)
+		frame := renderFrame(cfg, converter, gsProcessor, adjuster, p, viewport.Crop(img))
+		pool.Put(img)
 
 		// Render output
-		fmt.Print("\033[H") // Move cursor to top-left (home)
-		fmt.Print("\033[J") // Clear screen from cursor to end of screen
-		fmt.Print(output)   // Print the ASCII/ANSI image
+		fmt.Print(renderer.Render(frame))
 
 		// Update and display FPS if requested
 		if cfg.ShowFPS {
@@ -152,28 +364,118 @@ func run(ctx context.Context) error {
 				fpsa += f
 			}
 
-			// Calculate position for FPS display
-			ansiHeightMultiplier := uint(1)
-			if cfg.ANSI {
-				ansiHeightMultiplier = 2
-			}
+			// Print FPS below the frame, or over its last row if the frame
+			// fills the terminal
+			_, frameRows := frame.Size()
+			row := fpsRow(frameRows, termRows())
+			fmt.Printf("\033[%d;1H", row)
+			fmt.Print(fpsOverlay(fpsa/float64(len(fps)), async))
+			fmt.Print("\033[K") // Clear the rest of a longer previous counter
+			renderer.Overwritten(row - 1)
+		}
+	}
+}
 
-			// Move cursor to the bottom and print FPS
-			// Safe conversion with bounds checking
-			const maxInt = int(^uint(0) >> 1)
-			heightDiv := termHeight / ansiHeightMultiplier
-			var cursorLine int
-			if heightDiv > uint(maxInt-1) {
-				cursorLine = maxInt // Cap at max int to avoid overflow
-			} else {
-				cursorLine = int(heightDiv) + 1
-			}
-			// Ensure cursor line is positive
-			if cursorLine < 1 {
-				cursorLine = 1
-			}
-			fmt.Printf("\033[%d;0H", cursorLine)
-			fmt.Printf("FPS: %.0f", fpsa/float64(len(fps)))
+// resize adapts the output to a resized terminal: it takes over the new
+// dimensions, rescales the greenscreen background, lets the camera deliver
+// frames of the new size and clears the screen of the old frame, so the next
+// one is drawn in full.
+func resize(cfg *config.Config, gsProcessor *greenscreen.Processor, sizer *frameSizer, renderer *screen.Renderer) error {
+	if !cfg.UpdateTermSize() {
+		return nil
+	}
+
+	if gsProcessor != nil {
+		termWidth, termHeight := cfg.GetDisplayDimensions()
+		if err := gsProcessor.Resize(termWidth, termHeight); err != nil {
+			return fmt.Errorf("error resizing background samples: %w", err)
 		}
 	}
+	sizer.setSize(maxFrameSize(cfg))
+
+	fmt.Print("\033[2J")
+	renderer.Invalidate()
+	return nil
+}
+
+// newRenderer returns the renderer drawing frames as configured by cfg. With
+// -delta=false every frame is redrawn in full.
+func newRenderer(cfg *config.Config) *screen.Renderer {
+	if !cfg.Delta {
+		return screen.NewFullRenderer()
+	}
+	return screen.NewRenderer(cfg.DeltaThreshold)
+}
+
+// statusMessage describes a frame read error for display in the viewport.
+func statusMessage(err error) string {
+	if errors.IsCameraLost(err) {
+		return "Camera disconnected — retrying..."
+	}
+	return fmt.Sprintf("Error reading frame: %v", err)
+}
+
+// fpsRow returns the screen row, counted from 1, to print the FPS counter
+// on: the one below a frame of frameRows rows, or the last row of a terminal
+// of termRows rows if there is none below. A termRows of 0 is unknown.
+func fpsRow(frameRows, termRows int) int {
+	if termRows > 0 && frameRows >= termRows {
+		return termRows
+	}
+	return frameRows + 1
+}
+
+// termRows returns the number of rows of the terminal, or 0 if unknown.
+func termRows() int {
+	_, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return rows
+}
+
+// fpsOverlay formats the FPS counter, followed by the capture worker's frame
+// counters if frames are captured asynchronously.
+func fpsOverlay(fps float64, async *camera.AsyncSource) string {
+	if async == nil {
+		return fmt.Sprintf("FPS: %.0f", fps)
+	}
+
+	stats := async.Stats()
+	return fmt.Sprintf("FPS: %.0f | captured %d, dropped %d, rendered %d", fps, stats.Captured, stats.Dropped, stats.Rendered)
+}
+
+// renderFrame runs a frame through the resize, greenscreen, adjustment and
+// ASCII/ANSI conversion pipeline and returns the cells to show.
+func renderFrame(cfg *config.Config, converter *ascii.Converter, gsProcessor *greenscreen.Processor, adjuster *camera.Adjuster, p termenv.Profile, img image.Image) *screen.Buffer {
+	termWidth, termHeight := cfg.GetDisplayDimensions()
+	scaledWidth, scaledHeight := cfg.GetScaledDimensions()
+
+	// Resize image based on calculated dimensions, keeping the aspect ratio
+	// of the frame unless it is stretched
+	resizedImg := camera.ScaleImage(img, scaledWidth, scaledHeight, camera.ScaleMode(cfg.Scale), cfg.PixelAspect())
+
+	// Apply greenscreen effect if enabled
+	if cfg.UseGreenscreen && gsProcessor != nil {
+		if rgbaImg, ok := resizedImg.(*image.RGBA); ok {
+			gsProcessor.Apply(rgbaImg)
+			resizedImg = rgbaImg
+		}
+	}
+
+	// Adjust after the greenscreen, which compares frames to the unadjusted
+	// background samples
+	if adjuster != nil {
+		resizedImg = adjuster.Apply(resizedImg)
+	}
+
+	switch {
+	case cfg.ANSI:
+		return converter.ImageToBlocksScreen(p, resizedImg, ascii.BlockMode(cfg.Blocks))
+	case cfg.Shapes:
+		return converter.ImageToShapesScreen(p, resizedImg)
+	case cfg.Braille:
+		return converter.ImageToBrailleScreen(p, resizedImg)
+	}
+	return converter.ImageToASCIIScreen(termWidth, termHeight, p, resizedImg)
 }
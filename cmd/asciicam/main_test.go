@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/muesli/asciicam/internal/ascii"
+	"github.com/muesli/asciicam/internal/camera"
+	"github.com/muesli/asciicam/internal/config"
+	"github.com/muesli/asciicam/internal/errors"
+	"github.com/muesli/asciicam/internal/input"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenConfig returns a configuration with fixed output dimensions so
+// rendering does not depend on the terminal running the tests.
+func goldenConfig(ansi bool) *config.Config {
+	cfg := config.NewConfig()
+	cfg.ANSI = ansi
+	return validGoldenConfig(cfg)
+}
+
+// validGoldenConfig sets the fixed golden dimensions on cfg and validates it.
+// Frames are stretched, as fitting them depends on the cell shape the
+// terminal reports.
+func validGoldenConfig(cfg *config.Config) *config.Config {
+	cfg.Input = config.TestPatternPrefix + "bars"
+	cfg.Width = 32
+	cfg.Height = 8
+	cfg.Scale = config.ScaleStretch
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func TestRenderFrame_Golden(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		frame   int
+		mode    func(cfg *config.Config) // render mode, plain ASCII if nil
+		profile termenv.Profile
+	}{
+		{"bars_ascii", "bars", 0, nil, termenv.Ascii},
+		{"gradient_ascii", "gradient", 0, nil, termenv.Ascii},
+		{"bounce_ascii", "bounce", 12, nil, termenv.Ascii},
+		{"bounce_shapes", "bounce", 12, func(cfg *config.Config) { cfg.Shapes = true }, termenv.Ascii},
+		{"bounce_braille", "bounce", 12, func(cfg *config.Config) { cfg.Braille = true }, termenv.Ascii},
+		{"bars_ansi256", "bars", 0, func(cfg *config.Config) { cfg.ANSI = true }, termenv.ANSI256},
+		{"bounce_sextant256", "bounce", 12, func(cfg *config.Config) { cfg.ANSI, cfg.Blocks = true, "sextant" }, termenv.ANSI256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := camera.NewTestPattern(tt.pattern, 320, 180, 0)
+			if err != nil {
+				t.Fatalf("NewTestPattern returned error: %v", err)
+			}
+
+			cfg := config.NewConfig()
+			if tt.mode != nil {
+				tt.mode(cfg)
+			}
+			validGoldenConfig(cfg)
+			got := renderFrame(cfg, ascii.NewConverter(), nil, nil, tt.profile, source.Frame(tt.frame)).String()
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file (run with -update): %v", err)
+			}
+
+			if got != string(expected) {
+				t.Errorf("Output does not match %s:\n%s\nexpected:\n%s", golden, got, expected)
+			}
+		})
+	}
+}
+
+func TestRenderFrame_Rotated(t *testing.T) {
+	source, err := camera.NewTestPattern("bars", 320, 180, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	for _, ansi := range []bool{false, true} {
+		cfg := goldenConfig(ansi)
+		cfg.Rotate = 90
+
+		img := orientFrame(orientation(cfg), nil, source.Frame(0))
+		if b := img.Bounds(); b.Dx() != 180 || b.Dy() != 320 {
+			t.Fatalf("Expected 180x320 rotated frame, got %dx%d", b.Dx(), b.Dy())
+		}
+
+		// The rotated frame still fills the whole viewport
+		got := renderFrame(cfg, ascii.NewConverter(), nil, nil, termenv.Ascii, img).String()
+		lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+		if len(lines) != 8 {
+			t.Errorf("ANSI %v: expected 8 lines, got %d", ansi, len(lines))
+		}
+		for _, line := range lines {
+			n := utf8.RuneCountInString(line)
+			if ansi {
+				n = strings.Count(line, "▀")
+			}
+			if n != 32 {
+				t.Errorf("ANSI %v: expected 32 columns, got %d", ansi, n)
+				break
+			}
+		}
+	}
+}
+
+func TestRenderFrame_Fit(t *testing.T) {
+	source, err := camera.NewTestPattern("bars", 320, 180, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	cfg := goldenConfig(false)
+	cfg.Scale = config.ScaleFit
+	cfg.CellAspect = 2
+
+	// 16:9 on cells twice as tall as wide is 28 columns wide in 32x8,
+	// leaving black bars of two columns on either side
+	got := renderFrame(cfg, ascii.NewConverter(), nil, nil, termenv.Ascii, source.Frame(0)).String()
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if len(line) != 32 || line[:2] != "  " || line[2] == ' ' || line[30:] != "  " {
+			t.Fatalf("Expected frame pillarboxed by two columns, got %q", line)
+		}
+	}
+}
+
+func TestRenderFrame_Adjustments(t *testing.T) {
+	source, err := camera.NewTestPattern("bars", 320, 180, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	// Full brightness turns every bar into the densest glyph
+	cfg := goldenConfig(false)
+	cfg.AdjustBrightness = 1
+	adjuster := camera.NewAdjuster(adjustments(cfg))
+	got := renderFrame(cfg, ascii.NewConverter(), nil, adjuster, termenv.Ascii, source.Frame(0)).String()
+
+	ramp, _ := ascii.ParseCharset(ascii.DefaultCharset)
+	densest := string([]rune(ramp)[len([]rune(ramp))-1])
+	if n := strings.Count(got, densest); n != 32*8 {
+		t.Errorf("Expected %d cells of %q, got %d:\n%s", 32*8, densest, n, got)
+	}
+}
+
+func TestControls_ResetAdjustments(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AdjustBrightness = 0.2
+	cfg.AutoLevels = config.AutoLevelsStretch
+	configured := adjustments(cfg)
+	ctrl := &controls{adjuster: camera.NewAdjuster(configured), configured: configured}
+
+	// r goes back to the adjustments from the command line, not to none
+	for _, key := range []input.Key{'B', 'T', 'a', 'r'} {
+		ctrl.handleKey(key)
+	}
+	if got := ctrl.adjuster.Adjustments(); got != configured {
+		t.Errorf("Expected %+v after reset, got %+v", configured, got)
+	}
+}
+
+func TestValidate_TestPatterns(t *testing.T) {
+	// The configuration accepts every pattern the camera package has
+	for _, name := range camera.TestPatterns() {
+		cfg := config.NewConfig()
+		cfg.Input = config.TestPatternPrefix + name
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() returned error for test pattern %s: %v", name, err)
+		}
+	}
+}
+
+func TestOpenSource_TestPattern(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Input = config.TestPatternPrefix + "checkerboard"
+	cfg.CamWidth = 64
+	cfg.CamHeight = 48
+
+	source, err := openSource(cfg)
+	if err != nil {
+		t.Fatalf("openSource returned error: %v", err)
+	}
+	defer source.Close()
+
+	img, err := source.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 48 {
+		t.Errorf("Expected 64x48 frame, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestFPSOverlay(t *testing.T) {
+	if got := fpsOverlay(29.6, nil); got != "FPS: 30" {
+		t.Errorf("Expected plain FPS counter, got %q", got)
+	}
+
+	pattern, err := camera.NewTestPattern("bars", 8, 8, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+	async := camera.NewAsyncSource(pattern, 2)
+	defer async.Close()
+
+	if _, err := async.ReadFrameWithContext(context.Background()); err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	if got := fpsOverlay(30, async); !strings.Contains(got, "rendered 1") {
+		t.Errorf("Expected frame counters in overlay, got %q", got)
+	}
+}
+
+func TestFPSRow(t *testing.T) {
+	tests := []struct {
+		frameRows, termRows, want int
+	}{
+		{20, 24, 21}, // below the frame
+		{24, 24, 24}, // over the last row of a frame filling the terminal
+		{30, 24, 24}, // over the last row of the terminal
+		{20, 0, 21},  // below the frame in a terminal of unknown size
+	}
+	for _, tt := range tests {
+		if got := fpsRow(tt.frameRows, tt.termRows); got != tt.want {
+			t.Errorf("fpsRow(%d, %d) = %d, expected %d", tt.frameRows, tt.termRows, got, tt.want)
+		}
+	}
+}
+
+func TestStatusMessage(t *testing.T) {
+	lost := errors.NewCameraError(0, "read", errors.ErrCameraLost)
+	if got := statusMessage(lost); !strings.Contains(got, "disconnected") {
+		t.Errorf("Expected disconnected status, got %q", got)
+	}
+
+	other := errors.NewCameraError(0, "read", errors.ErrCameraReadFailed)
+	if got := statusMessage(other); !strings.Contains(got, "Error reading frame") {
+		t.Errorf("Expected read error status, got %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/muesli/asciicam/internal/camera"
+	"github.com/muesli/asciicam/internal/config"
+)
+
+// cameraProperties converts the camera properties set in the configuration.
+func cameraProperties(p config.CameraProperties) camera.Properties {
+	props := camera.Properties{}
+
+	values := map[camera.Property]*float64{
+		camera.PropertyExposure:     p.Exposure,
+		camera.PropertyGain:         p.Gain,
+		camera.PropertyFocus:        p.Focus,
+		camera.PropertyBrightness:   p.Brightness,
+		camera.PropertyContrast:     p.Contrast,
+		camera.PropertySaturation:   p.Saturation,
+		camera.PropertyWhiteBalance: p.WhiteBalance,
+		camera.PropertyFPS:          p.FPS,
+	}
+	for prop, v := range values {
+		if v != nil {
+			props[prop] = *v
+		}
+	}
+
+	switches := map[camera.Property]*bool{
+		camera.PropertyAutoExposure:     p.AutoExposure,
+		camera.PropertyAutoFocus:        p.AutoFocus,
+		camera.PropertyAutoWhiteBalance: p.AutoWhiteBalance,
+	}
+	for prop, v := range switches {
+		if v == nil {
+			continue
+		}
+		props[prop] = 0
+		if *v {
+			props[prop] = 1
+		}
+	}
+
+	return props
+}
+
+// applyProperties sets the requested camera properties on source and warns
+// on w about every property the camera did not apply.
+func applyProperties(w io.Writer, source camera.FrameSource, props camera.Properties) {
+	if len(props) == 0 {
+		return
+	}
+
+	setter, ok := source.(camera.PropertySetter)
+	if !ok {
+		fmt.Fprintln(w, "Warning: camera properties are not supported by this input")
+		return
+	}
+
+	for _, r := range setter.SetProperties(props) {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(w, "Warning: camera rejected %s=%g: %v\n", r.Property, r.Requested, r.Err)
+		case r.Ignored():
+			fmt.Fprintf(w, "Warning: camera ignored %s=%g, using %g\n", r.Property, r.Requested, r.Actual)
+		}
+	}
+}
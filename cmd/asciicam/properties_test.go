@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/asciicam/internal/camera"
+	"github.com/muesli/asciicam/internal/config"
+)
+
+// propertySource is a test pattern whose camera accepts brightness but
+// clamps exposure to 100.
+type propertySource struct {
+	*camera.TestPattern
+}
+
+func (s propertySource) SetProperties(props camera.Properties) []camera.PropertyResult {
+	var results []camera.PropertyResult
+	for prop, v := range props {
+		actual := v
+		if prop == camera.PropertyExposure {
+			actual = min(v, 100)
+		}
+		results = append(results, camera.PropertyResult{Property: prop, Requested: v, Actual: actual})
+	}
+	return results
+}
+
+func TestCameraProperties(t *testing.T) {
+	exposure, autoFocus := 200.0, true
+	props := cameraProperties(config.CameraProperties{
+		Exposure:  &exposure,
+		AutoFocus: &autoFocus,
+	})
+
+	if len(props) != 2 || props[camera.PropertyExposure] != 200 || props[camera.PropertyAutoFocus] != 1 {
+		t.Errorf("Unexpected properties: %v", props)
+	}
+}
+
+func TestApplyProperties(t *testing.T) {
+	pattern, err := camera.NewTestPattern("bars", 32, 32, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	applyProperties(&buf, propertySource{pattern}, camera.Properties{
+		camera.PropertyBrightness: 10,
+		camera.PropertyExposure:   200,
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, "ignored exposure=200, using 100") {
+		t.Errorf("Expected a warning about exposure, got %q", out)
+	}
+	if strings.Contains(out, "brightness") {
+		t.Errorf("Expected no warning about brightness, got %q", out)
+	}
+
+	// Sources without camera properties warn once
+	buf.Reset()
+	applyProperties(&buf, pattern, camera.Properties{camera.PropertyGain: 1})
+	if strings.Count(buf.String(), "Warning") != 1 {
+		t.Errorf("Expected a single warning, got %q", buf.String())
+	}
+}
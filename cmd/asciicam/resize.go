@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/muesli/asciicam/internal/camera"
+)
+
+// resizeDebounce is how long the terminal size has to stay put before the
+// output is resized, so dragging a window edge resizes once at the end.
+const resizeDebounce = 150 * time.Millisecond
+
+// watchResize returns a channel that receives a value after the terminal was
+// resized, once it stayed at its new size for resizeDebounce. The channel
+// never receives on platforms without resize notifications.
+func watchResize(ctx context.Context) <-chan struct{} {
+	sigs := make(chan os.Signal, 1)
+	notifyResize(sigs)
+	return debounce(ctx, sigs, resizeDebounce)
+}
+
+// debounce forwards bursts of signals from in as a single value on the
+// returned channel, sent once no signal arrived for delay. Values are dropped
+// rather than queued while the reader is busy.
+func debounce(ctx context.Context, in <-chan os.Signal, delay time.Duration) <-chan struct{} {
+	out := make(chan struct{}, 1)
+
+	go func() {
+		timer := time.NewTimer(delay)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-in:
+				timer.Reset(delay)
+			case <-timer.C:
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// frameSizer keeps the open camera shrinking frames to the largest size
+// worth capturing. The render loop changes the size when the terminal is
+// resized, while the capture worker attaches cameras it reopens.
+type frameSizer struct {
+	mu            sync.Mutex
+	camera        camera.Downscaler
+	width, height uint
+}
+
+// attach applies the current size to a newly opened camera and keeps it
+// updated from now on.
+func (s *frameSizer) attach(d camera.Downscaler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.camera = d
+	d.SetMaxSize(s.width, s.height)
+}
+
+// setSize changes the size and applies it to the attached camera.
+func (s *frameSizer) setSize(width, height uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.width, s.height = width, height
+	if s.camera != nil {
+		s.camera.SetMaxSize(width, height)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package main
+
+import "os"
+
+// notifyResize does nothing, as this platform has no resize signal.
+func notifyResize(chan<- os.Signal) {}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/muesli/asciicam/internal/screen"
+)
+
+func TestDebounce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan os.Signal)
+	out := debounce(ctx, in, 50*time.Millisecond)
+
+	// A burst of signals, as sent while dragging a window edge
+	for i := 0; i < 5; i++ {
+		in <- syscall.SIGINT
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-out:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a value after the burst")
+	}
+
+	select {
+	case <-out:
+		t.Error("Expected a single value for the burst")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDebounce_Quiet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := debounce(ctx, make(chan os.Signal), 10*time.Millisecond)
+	select {
+	case <-out:
+		t.Error("Expected no value without signals")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// fakeDownscaler records the size it was asked to shrink frames to.
+type fakeDownscaler struct {
+	width, height uint
+}
+
+func (d *fakeDownscaler) SetMaxSize(width, height uint) {
+	d.width, d.height = width, height
+}
+
+func TestFrameSizer(t *testing.T) {
+	sizer := &frameSizer{}
+	sizer.setSize(640, 360)
+
+	cam := &fakeDownscaler{}
+	sizer.attach(cam)
+	if cam.width != 640 || cam.height != 360 {
+		t.Errorf("Expected attached camera to get 640x360, got %dx%d", cam.width, cam.height)
+	}
+
+	sizer.setSize(800, 450)
+	if cam.width != 800 || cam.height != 450 {
+		t.Errorf("Expected camera to follow to 800x450, got %dx%d", cam.width, cam.height)
+	}
+
+	// A reopened camera replaces the lost one
+	reopened := &fakeDownscaler{}
+	sizer.attach(reopened)
+	sizer.setSize(320, 180)
+	if reopened.width != 320 || cam.width != 800 {
+		t.Error("Expected only the reopened camera to be updated")
+	}
+}
+
+func TestResize_ExplicitSize(t *testing.T) {
+	cfg := goldenConfig(false)
+	sizer := &frameSizer{}
+
+	if err := resize(cfg, nil, sizer, screen.NewRenderer(0)); err != nil {
+		t.Fatalf("resize returned error: %v", err)
+	}
+	if w, h := cfg.GetDisplayDimensions(); w != 32 || h != 8 {
+		t.Errorf("Expected explicit 32x8 to stay, got %dx%d", w, h)
+	}
+}
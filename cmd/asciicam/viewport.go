@@ -0,0 +1,73 @@
+package main
+
+import (
+	"image"
+	"time"
+
+	"github.com/muesli/asciicam/internal/camera"
+	"github.com/muesli/asciicam/internal/config"
+)
+
+const (
+	// faceDetectInterval is how often the face is searched for when the
+	// digital zoom follows it. Detection is far slower than rendering.
+	faceDetectInterval = 250 * time.Millisecond
+	// zoomHeadroom is the digital zoom cameras keep enough resolution for
+	// when shrinking frames, unless a stronger zoom is configured.
+	zoomHeadroom = 4.0
+)
+
+// newViewport returns the digital zoom viewport configured by cfg.
+func newViewport(cfg *config.Config) *camera.Viewport {
+	return camera.NewViewport(cfg.CenterX, cfg.CenterY, cfg.Zoom)
+}
+
+// maxFrameSize returns the largest frame size worth capturing for cfg: the
+// output size, turned if frames are rotated on their side, with room for
+// zooming in.
+func maxFrameSize(cfg *config.Config) (uint, uint) {
+	w, h := orientation(cfg).Size(cfg.GetScaledDimensions())
+	headroom := max(cfg.Zoom, zoomHeadroom)
+	return uint(float64(w) * headroom), uint(float64(h) * headroom)
+}
+
+// faceTracker keeps a viewport centered on the face found in the frames.
+// A nil faceTracker does nothing.
+type faceTracker struct {
+	detector camera.FaceDetector
+	viewport *camera.Viewport
+	next     time.Time
+}
+
+// newFaceTracker loads the face detector configured by cfg, if any.
+func newFaceTracker(cfg *config.Config, viewport *camera.Viewport) (*faceTracker, error) {
+	if cfg.FaceCascade == "" {
+		return nil, nil
+	}
+
+	detector, err := camera.NewFaceDetector(cfg.FaceCascade)
+	if err != nil {
+		return nil, err
+	}
+	return &faceTracker{detector: detector, viewport: viewport}, nil
+}
+
+// track recenters the viewport on the face in img, unless it looked for a
+// face less than faceDetectInterval ago.
+func (f *faceTracker) track(img image.Image) {
+	if f == nil || time.Now().Before(f.next) {
+		return
+	}
+	f.next = time.Now().Add(faceDetectInterval)
+
+	if face, ok := f.detector.DetectFace(img); ok {
+		f.viewport.FollowFace(img.Bounds(), face)
+	}
+}
+
+// Close releases the face detector.
+func (f *faceTracker) Close() {
+	if f != nil {
+		f.detector.Close()
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/muesli/asciicam/internal/ascii"
+	"github.com/muesli/asciicam/internal/camera"
+	"github.com/muesli/asciicam/internal/input"
+	"github.com/muesli/termenv"
+)
+
+func TestMaxFrameSize(t *testing.T) {
+	cfg := goldenConfig(false)
+	if w, h := maxFrameSize(cfg); w != 128 || h != 32 {
+		t.Errorf("Expected 128x32 with zoom headroom, got %dx%d", w, h)
+	}
+
+	cfg.Zoom = 8
+	cfg.Rotate = 270
+	if w, h := maxFrameSize(cfg); w != 64 || h != 256 {
+		t.Errorf("Expected 64x256 for rotated 8x zoom, got %dx%d", w, h)
+	}
+}
+
+func TestControls_Viewport(t *testing.T) {
+	viewport := camera.NewViewport(0.5, 0.5, 1)
+	ctrl := &controls{viewport: viewport}
+	bounds := image.Rect(0, 0, 100, 100)
+
+	for _, key := range []input.Key{'i', 'i', 'i', 'l', 'j'} {
+		ctrl.handleKey(key)
+	}
+	for i := 0; i < 100; i++ {
+		viewport.Crop(image.NewRGBA(bounds))
+	}
+
+	r := viewport.Region(bounds)
+	if viewport.Magnification() <= 1 {
+		t.Errorf("Expected zoomed in view, got magnification %g", viewport.Magnification())
+	}
+	if c := r.Min.Add(r.Max).Div(2); c.X <= 50 || c.Y <= 50 {
+		t.Errorf("Expected view panned right and down, got center %v", c)
+	}
+
+	ctrl.handleKey('c')
+	for i := 0; i < 100; i++ {
+		viewport.Crop(image.NewRGBA(bounds))
+	}
+	if got := viewport.Region(bounds); got != bounds {
+		t.Errorf("Expected reset to the whole frame, got %v", got)
+	}
+}
+
+func TestNewFaceTracker_Disabled(t *testing.T) {
+	cfg := goldenConfig(false)
+	faces, err := newFaceTracker(cfg, newViewport(cfg))
+	if err != nil || faces != nil {
+		t.Fatalf("Expected no face tracker, got %v, %v", faces, err)
+	}
+
+	// A nil tracker is safe to use
+	faces.track(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	faces.Close()
+}
+
+func TestRenderFrame_DigitalZoom(t *testing.T) {
+	source, err := camera.NewTestPattern("bars", 320, 180, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	cfg := goldenConfig(false)
+	cfg.Zoom = 8
+	cfg.CenterX = 0
+
+	// The leftmost bar is white, the cropped region fills the viewport
+	got := renderFrame(cfg, ascii.NewConverter(), nil, nil, termenv.Ascii, newViewport(cfg).Crop(source.Frame(0))).String()
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Expected 8 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if len(line) != 32 || strings.Trim(line, line[:1]) != "" {
+			t.Fatalf("Expected 32 identical characters, got %q", line)
+		}
+	}
+}
@@ -7,6 +7,8 @@ require (
 	github.com/muesli/termenv v0.16.0
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	gocv.io/x/gocv v0.25.0
+	golang.org/x/image v0.25.0
+	golang.org/x/sys v0.32.0
 	golang.org/x/term v0.31.0
 )
 
@@ -14,5 +16,5 @@ require (
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/sys v0.32.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
@@ -0,0 +1,183 @@
+package ascii
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/muesli/termenv"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"github.com/muesli/asciicam/internal/screen"
+)
+
+// BlockMode selects the block characters ANSI output is drawn with. Each
+// cell shows a grid of pixels in two colors, one in the foreground and one in
+// the background.
+type BlockMode string
+
+// Block modes.
+const (
+	// BlocksHalf stacks two pixels in each cell using the upper half block.
+	BlocksHalf BlockMode = "half"
+	// BlocksQuadrant shows 2x2 pixels per cell using quadrant blocks.
+	BlocksQuadrant BlockMode = "quadrant"
+	// BlocksSextant shows 2x3 pixels per cell using the sextant blocks added
+	// in Unicode 13, which not all fonts have.
+	BlocksSextant BlockMode = "sextant"
+)
+
+// ParseBlockMode checks that mode names a block mode.
+func ParseBlockMode(mode string) (BlockMode, error) {
+	switch m := BlockMode(mode); m {
+	case BlocksHalf, BlocksQuadrant, BlocksSextant:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w: unknown block mode %q, want %s, %s or %s", errors.ErrInvalidConfig, mode, BlocksHalf, BlocksQuadrant, BlocksSextant)
+}
+
+// CellSize returns how many pixels wide and tall each cell is in mode.
+func (m BlockMode) CellSize() (int, int) {
+	switch m {
+	case BlocksQuadrant:
+		return 2, 2
+	case BlocksSextant:
+		return 2, 3
+	}
+	return 1, 2
+}
+
+// quadrants maps the pixels in the foreground, as bits numbered row by row
+// from the top left, to quadrant blocks.
+var quadrants = [16]rune{
+	' ', '▘', '▝', '▀', '▖', '▌', '▞', '▛',
+	'▗', '▚', '▐', '▜', '▄', '▙', '▟', '█',
+}
+
+// sextant returns the sextant block for the pixels in the foreground, as bits
+// numbered row by row from the top left. Unicode leaves out the patterns
+// that other blocks already cover: blank, the left and right halves and full.
+func sextant(mask int) rune {
+	const left, right = 0b010101, 0b101010
+	switch {
+	case mask == 0:
+		return ' '
+	case mask == left:
+		return '▌'
+	case mask == right:
+		return '▐'
+	case mask == 0b111111:
+		return '█'
+	case mask > right:
+		return rune(0x1FB00 + mask - 3)
+	case mask > left:
+		return rune(0x1FB00 + mask - 2)
+	}
+	return rune(0x1FB00 + mask - 1)
+}
+
+// ImageToBlocks converts an image to colored blocks of the given mode. Each
+// cell splits its pixels into the two groups whose mean colors represent
+// them best, and draws them as the foreground and background of the block
+// with the matching shape. BlocksHalf is the same as ImageToANSI.
+func (c *Converter) ImageToBlocks(p termenv.Profile, img image.Image, mode BlockMode) string {
+	return c.ImageToBlocksScreen(p, img, mode).String()
+}
+
+// ImageToBlocksScreen is ImageToBlocks drawing into a screen buffer.
+func (c *Converter) ImageToBlocksScreen(p termenv.Profile, img image.Image, mode BlockMode) *screen.Buffer {
+	if mode != BlocksQuadrant && mode != BlocksSextant {
+		return c.ImageToANSIScreen(p, img)
+	}
+
+	img = c.ditherPalette(p, img)
+	b := img.Bounds()
+	w, h := mode.CellSize()
+	n := w * h
+	pixels := make([][3]int, n)
+	buf := screen.NewBuffer(b.Dx()/w, b.Dy()/h)
+
+	for y := b.Min.Y; y+h <= b.Max.Y; y += h {
+		for x := b.Min.X; x+w <= b.Max.X; x += w {
+			for i := range pixels {
+				r, g, bl, _ := img.At(x+i%w, y+i/w).RGBA()
+				pixels[i] = [3]int{int(r >> 8), int(g >> 8), int(bl >> 8)}
+			}
+
+			mask, fg, bg := splitColors(pixels)
+			glyph := quadrants[mask&15]
+			if mode == BlocksSextant {
+				glyph = sextant(mask)
+			}
+			buf.Set((x-b.Min.X)/w, (y-b.Min.Y)/h, screen.Cell{
+				Glyph: glyph,
+				FG:    p.FromColor(fg),
+				BG:    p.FromColor(bg),
+			})
+		}
+	}
+
+	return buf
+}
+
+// splitColors finds the partition of pixels into two groups with the least
+// squared error to their group's mean color. It returns the pixels of the
+// foreground group as a bit mask along with the mean colors of both groups.
+// The last pixel is always in the foreground, so a cell of a single color is
+// a full block.
+func splitColors(pixels [][3]int) (int, color.RGBA, color.RGBA) {
+	n := len(pixels)
+
+	bestMask, bestErr := 0, -1.0
+	var bestSum [2][3]int
+	var bestCount [2]int
+	// Trying the full block first keeps it for cells of a single color
+	for mask := 1<<n - 1; mask >= 1<<(n-1); mask-- {
+		var sum [2][3]int
+		var sumSq, count [2]int
+		for i, px := range pixels {
+			group := (mask >> i) & 1
+			count[group]++
+			for ch, v := range px {
+				sum[group][ch] += v
+				sumSq[group] += v * v
+			}
+		}
+
+		// The squared error of a group is its sum of squares minus its
+		// squared sum over its size
+		var errSum float64
+		for g := range count {
+			if count[g] == 0 {
+				continue
+			}
+			var sq int
+			for _, s := range sum[g] {
+				sq += s * s
+			}
+			errSum += float64(sumSq[g]) - float64(sq)/float64(count[g])
+		}
+
+		if bestErr < 0 || errSum < bestErr {
+			bestMask, bestErr = mask, errSum
+			bestSum, bestCount = sum, count
+		}
+	}
+
+	fg := meanColor(bestSum[1], bestCount[1])
+	bg := fg
+	if bestCount[0] > 0 {
+		bg = meanColor(bestSum[0], bestCount[0])
+	}
+	return bestMask, fg, bg
+}
+
+// meanColor returns the opaque color with the channel sums divided by count.
+func meanColor(sum [3]int, count int) color.RGBA {
+	return color.RGBA{
+		R: uint8(sum[0] / count),
+		G: uint8(sum[1] / count),
+		B: uint8(sum[2] / count),
+		A: 255,
+	}
+}
@@ -0,0 +1,152 @@
+package ascii
+
+import (
+	stderrors "errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestParseBlockMode(t *testing.T) {
+	for _, mode := range []string{"half", "quadrant", "sextant"} {
+		if got, err := ParseBlockMode(mode); err != nil || string(got) != mode {
+			t.Errorf("ParseBlockMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+
+	if _, err := ParseBlockMode("octant"); !stderrors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestBlockMode_CellSize(t *testing.T) {
+	tests := []struct {
+		mode BlockMode
+		w, h int
+	}{
+		{BlocksHalf, 1, 2},
+		{BlocksQuadrant, 2, 2},
+		{BlocksSextant, 2, 3},
+	}
+
+	for _, tt := range tests {
+		if w, h := tt.mode.CellSize(); w != tt.w || h != tt.h {
+			t.Errorf("%s: expected %dx%d, got %dx%d", tt.mode, tt.w, tt.h, w, h)
+		}
+	}
+}
+
+func TestSextant(t *testing.T) {
+	tests := []struct {
+		mask int
+		want rune
+	}{
+		{0, ' '},
+		{0b000001, '\U0001FB00'},
+		{0b000011, '\U0001FB02'},
+		{0b010101, '▌'},
+		{0b010110, '\U0001FB14'},
+		{0b101010, '▐'},
+		{0b101011, '\U0001FB28'},
+		{0b111110, '\U0001FB3B'},
+		{0b111111, '█'},
+	}
+
+	for _, tt := range tests {
+		if got := sextant(tt.mask); got != tt.want {
+			t.Errorf("sextant(%06b) = %U, want %U", tt.mask, got, tt.want)
+		}
+	}
+
+	// Every pattern has its own glyph
+	seen := make(map[rune]int)
+	for mask := 0; mask < 64; mask++ {
+		if prev, ok := seen[sextant(mask)]; ok {
+			t.Errorf("Masks %06b and %06b share glyph %U", prev, mask, sextant(mask))
+		}
+		seen[sextant(mask)] = mask
+	}
+}
+
+// twoColorImage returns a width x height image where the pixels marked '#'
+// in rows are red and the others blue.
+func twoColorImage(rows []string) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, c := range row {
+			col := color.RGBA{0, 0, 255, 255}
+			if c == '#' {
+				col = color.RGBA{255, 0, 0, 255}
+			}
+			img.SetRGBA(x, y, col)
+		}
+	}
+	return img
+}
+
+func TestImageToBlocks(t *testing.T) {
+	// The last pixel always ends up in the foreground
+	tests := []struct {
+		name string
+		mode BlockMode
+		rows []string
+		want rune
+	}{
+		{"quadrant diagonal", BlocksQuadrant, []string{"#.", ".#"}, '▚'},
+		{"quadrant corner", BlocksQuadrant, []string{"..", ".#"}, '▗'},
+		{"quadrant uniform", BlocksQuadrant, []string{"##", "##"}, '█'},
+		{"sextant top", BlocksSextant, []string{"##", "..", ".."}, '\U0001FB39'},
+		{"sextant left", BlocksSextant, []string{"#.", "#.", "#."}, '▐'},
+	}
+
+	converter := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := converter.ImageToBlocks(termenv.TrueColor, twoColorImage(tt.rows), tt.mode)
+			if n := strings.Count(got, string(tt.want)); n != 1 {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestImageToBlocks_Colors(t *testing.T) {
+	img := twoColorImage([]string{"#.", "..", ".."})
+	got := NewConverter().ImageToBlocks(termenv.TrueColor, img, BlocksSextant)
+
+	// One color goes to the foreground and the other to the background
+	red, blue := "2;255;0;0", "2;0;0;255"
+	if !(strings.Contains(got, "38;"+red) && strings.Contains(got, "48;"+blue) ||
+		strings.Contains(got, "38;"+blue) && strings.Contains(got, "48;"+red)) {
+		t.Errorf("Expected red and blue split into foreground and background, got %q", got)
+	}
+}
+
+func TestImageToBlocks_Size(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 9, 10))
+	got := NewConverter().ImageToBlocks(termenv.Ascii, img, BlocksSextant)
+
+	// Pixels that do not fill a whole cell are left out
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if n := strings.Count(line, "█"); n != 4 {
+			t.Errorf("Line %d: expected 4 cells, got %d", i, n)
+		}
+	}
+}
+
+func TestImageToBlocks_Half(t *testing.T) {
+	img := twoColorImage([]string{"#.", ".#"})
+	converter := NewConverter()
+	if got, want := converter.ImageToBlocks(termenv.ANSI256, img, BlocksHalf), converter.ImageToANSI(termenv.ANSI256, img); got != want {
+		t.Errorf("Expected half blocks to match ImageToANSI, got %q, want %q", got, want)
+	}
+}
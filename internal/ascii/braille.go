@@ -0,0 +1,117 @@
+package ascii
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/muesli/termenv"
+
+	"github.com/muesli/asciicam/internal/screen"
+)
+
+// Braille patterns pack a grid of BrailleCellWidth x BrailleCellHeight dots
+// into every terminal cell.
+const (
+	BrailleCellWidth  = 2
+	BrailleCellHeight = 4
+
+	// brailleBlank is the braille pattern without any dots.
+	brailleBlank = 0x2800
+)
+
+// brailleDots holds the bit of each dot in a braille pattern, indexed by row
+// and column.
+var brailleDots = [BrailleCellHeight][BrailleCellWidth]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// SetBrailleDither makes ImageToBraille raise dots by ordered dithering, which
+// renders shades as dot density, instead of by comparing them to their
+// cell, which keeps outlines sharper.
+func (c *Converter) SetBrailleDither(dither bool) {
+	c.brailleDither = dither
+}
+
+// ImageToBraille converts an image to braille patterns, packing
+// BrailleCellWidth x BrailleCellHeight pixels into each cell as dots: eight
+// times the resolution of ImageToASCII. Bright pixels raise dots, or dark
+// ones if the ramp is inverted. Cells are colored by the mean color of the
+// dots they raise, unless a global color is set.
+func (c *Converter) ImageToBraille(p termenv.Profile, img image.Image) string {
+	return c.ImageToBrailleScreen(p, img).String()
+}
+
+// ImageToBrailleScreen is ImageToBraille drawing into a screen buffer.
+func (c *Converter) ImageToBrailleScreen(p termenv.Profile, img image.Image) *screen.Buffer {
+	b := img.Bounds()
+	bayer := bayer4()
+	colors := c.newPaletteDither(p, b.Dx()/BrailleCellWidth)
+	buf := screen.NewBuffer(b.Dx()/BrailleCellWidth, b.Dy()/BrailleCellHeight)
+
+	var levels [BrailleCellHeight][BrailleCellWidth]float64
+	var pixels [BrailleCellHeight][BrailleCellWidth]color.NRGBA
+	for y := b.Min.Y; y+BrailleCellHeight <= b.Max.Y; y += BrailleCellHeight {
+		for x := b.Min.X; x+BrailleCellWidth <= b.Max.X; x += BrailleCellWidth {
+			var mean float64
+			for dy := range levels {
+				for dx := range levels[dy] {
+					pixel := color.NRGBAModel.Convert(img.At(x+dx, y+dy)).(color.NRGBA)
+					level := intensity(pixel)
+					if c.invert {
+						level = 1 - level
+					}
+					pixels[dy][dx] = pixel
+					levels[dy][dx] = level
+					mean += level
+				}
+			}
+			mean /= BrailleCellWidth * BrailleCellHeight
+
+			pattern := rune(brailleBlank)
+			var r, g, bl, a, dots uint32
+			for dy := range levels {
+				for dx, level := range levels[dy] {
+					var threshold float64
+					if c.brailleDither {
+						threshold = bayer.at(x+dx, y+dy)
+					} else {
+						// Halfway between the cell's mean and mid gray: cells
+						// with detail are split at their mean, flat ones are
+						// all dots or none
+						threshold = (mean + 0.5) / 2
+					}
+					if level <= threshold {
+						continue
+					}
+
+					pattern |= brailleDots[dy][dx]
+					pixel := pixels[dy][dx]
+					r += uint32(pixel.R)
+					g += uint32(pixel.G)
+					bl += uint32(pixel.B)
+					a += uint32(pixel.A)
+					dots++
+				}
+			}
+
+			pos := image.Pt((x-b.Min.X)/BrailleCellWidth, (y-b.Min.Y)/BrailleCellHeight)
+			cell := screen.Cell{Glyph: pattern}
+			if dots > 0 {
+				mean := color.NRGBA{
+					R: uint8(r / dots),
+					G: uint8(g / dots),
+					B: uint8(bl / dots),
+					A: uint8(a / dots),
+				}
+				cell.FG = c.foreground(p, colors.quantize(pos.X, pos.Y, mean))
+			}
+			buf.Set(pos.X, pos.Y, cell)
+		}
+		colors.nextRow()
+	}
+
+	return buf
+}
@@ -0,0 +1,113 @@
+package ascii
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/muesli/termenv"
+)
+
+// grayImage returns a width x height image filled with level.
+func grayImage(width, height int, level uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = level
+	}
+	return img
+}
+
+func TestImageToBraille(t *testing.T) {
+	tests := []struct {
+		name string
+		set  []image.Point // pixels set to white on black
+		want string
+	}{
+		{"blank", nil, "⠀"},
+		{"top left", []image.Point{{0, 0}}, "⠁"},
+		{"bottom right", []image.Point{{1, 3}}, "⢀"},
+		{"left column", []image.Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}}, "⡇"},
+		{"bottom row", []image.Point{{0, 3}, {1, 3}}, "⣀"},
+	}
+
+	converter := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := grayImage(BrailleCellWidth, BrailleCellHeight, 0)
+			for _, pt := range tt.set {
+				img.SetGray(pt.X, pt.Y, color.Gray{Y: 255})
+			}
+			if got := converter.ImageToBraille(termenv.Ascii, img); got != tt.want+"\n" {
+				t.Errorf("Expected %q, got %q", tt.want+"\n", got)
+			}
+		})
+	}
+}
+
+func TestImageToBraille_Flat(t *testing.T) {
+	converter := NewConverter()
+	if got := converter.ImageToBraille(termenv.Ascii, grayImage(2, 4, 220)); got != "⣿\n" {
+		t.Errorf("Expected all dots for a bright cell, got %q", got)
+	}
+	if got := converter.ImageToBraille(termenv.Ascii, grayImage(2, 4, 40)); got != "⠀\n" {
+		t.Errorf("Expected no dots for a dark cell, got %q", got)
+	}
+
+	converter.SetInvert(true)
+	if got := converter.ImageToBraille(termenv.Ascii, grayImage(2, 4, 40)); got != "⣿\n" {
+		t.Errorf("Expected all dots for an inverted dark cell, got %q", got)
+	}
+}
+
+func TestImageToBraille_Dither(t *testing.T) {
+	converter := NewConverter()
+	converter.SetBrailleDither(true)
+
+	// Mid gray raises half of the dots
+	got := converter.ImageToBraille(termenv.Ascii, grayImage(4, 4, 128))
+	var dots int
+	for _, r := range strings.TrimSuffix(got, "\n") {
+		for bits := r - brailleBlank; bits != 0; bits >>= 1 {
+			dots += int(bits & 1)
+		}
+	}
+	if dots != 8 {
+		t.Errorf("Expected 8 of 16 dots for mid gray, got %d in %q", dots, got)
+	}
+
+	// The pattern only depends on the pixel position, so it does not
+	// shimmer between frames
+	if again := converter.ImageToBraille(termenv.Ascii, grayImage(4, 4, 128)); again != got {
+		t.Errorf("Expected the same pattern, got %q and %q", got, again)
+	}
+}
+
+func TestImageToBraille_Size(t *testing.T) {
+	// Pixels that do not fill a whole cell are left out
+	img := image.NewRGBA(image.Rect(0, 0, 4*BrailleCellWidth+1, 3*BrailleCellHeight+3))
+	got := NewConverter().ImageToBraille(termenv.Ascii, img)
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if n := utf8.RuneCountInString(line); n != 4 {
+			t.Errorf("Line %d: expected 4 cells, got %d", i, n)
+		}
+	}
+}
+
+func TestImageToBraille_Color(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, BrailleCellWidth, BrailleCellHeight))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{255, 0, 0, 255})
+
+	// Cells take the color of their dots, not of the dark pixels around them
+	got := NewConverter().ImageToBraille(termenv.TrueColor, img)
+	if !strings.Contains(got, "38;2;255;0;0") {
+		t.Errorf("Expected red foreground, got %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package ascii
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// CustomCharsetPrefix marks a charset given as the ramp itself rather than by
+// a preset name, e.g. "custom: .oO@".
+const CustomCharsetPrefix = "custom:"
+
+// DefaultCharset is the name of the ramp used by NewConverter.
+const DefaultCharset = "default"
+
+// Charsets maps preset names to character ramps. Ramps run from the glyph
+// with the least ink, shown for dark pixels, to the one with the most.
+var Charsets = map[string]string{
+	DefaultCharset: " .,:;i1tfLCG08@",
+	// Paul Bourke's 70 level ramp
+	"standard": ` .'` + "`" + `^",:;Il!i><~+_-?][}{1)(|\/tfjrxnuvczXYUJCLQ0OZmwqpdbkhao*#MW&8%B@$`,
+	// Paul Bourke's 10 level ramp
+	"short":  " .:-=+*#%@",
+	"blocks": " ░▒▓█",
+	"digits": " 1742359608",
+	"binary": "01",
+	// Braille patterns with zero to eight dots
+	"dots": " ⠁⠉⠋⠛⠟⠿⡿⣿",
+}
+
+// CharsetNames returns the names of the preset ramps, sorted.
+func CharsetNames() []string {
+	names := make([]string, 0, len(Charsets))
+	for name := range Charsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseCharset returns the ramp selected by a preset name or by a custom
+// ramp following CustomCharsetPrefix.
+func ParseCharset(charset string) (string, error) {
+	ramp, ok := strings.CutPrefix(charset, CustomCharsetPrefix)
+	if !ok {
+		if ramp, ok = Charsets[charset]; !ok {
+			return "", fmt.Errorf("%w: unknown charset %q, want one of %s or %s<ramp>", errors.ErrInvalidCharset, charset, strings.Join(CharsetNames(), ", "), CustomCharsetPrefix)
+		}
+	}
+
+	if err := validateRamp(ramp); err != nil {
+		return "", err
+	}
+	return ramp, nil
+}
+
+// validateRamp checks that ramp can tell at least two intensities apart.
+func validateRamp(ramp string) error {
+	if !utf8.ValidString(ramp) {
+		return fmt.Errorf("%w: ramp is not valid UTF-8", errors.ErrInvalidCharset)
+	}
+	if n := utf8.RuneCountInString(ramp); n < 2 {
+		return fmt.Errorf("%w: ramp needs at least two glyphs, got %d", errors.ErrInvalidCharset, n)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package ascii
+
+import (
+	stderrors "errors"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestCharsets(t *testing.T) {
+	wantLen := map[string]int{
+		"standard": 70,
+		"short":    10,
+		"blocks":   5,
+		"binary":   2,
+	}
+
+	for name, ramp := range Charsets {
+		if err := validateRamp(ramp); err != nil {
+			t.Errorf("Preset %s is invalid: %v", name, err)
+		}
+		if n, ok := wantLen[name]; ok && utf8.RuneCountInString(ramp) != n {
+			t.Errorf("Expected preset %s to have %d glyphs, got %d", name, n, utf8.RuneCountInString(ramp))
+		}
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	tests := []struct {
+		charset string
+		want    string
+		wantErr bool
+	}{
+		{"default", Charsets[DefaultCharset], false},
+		{"blocks", " ░▒▓█", false},
+		{"custom: .oO@", " .oO@", false},
+		{"custom:ab", "ab", false},
+		{"custom:a", "", true},
+		{"custom:", "", true},
+		{"custom:\xff\xfe", "", true},
+		{"unknown", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.charset, func(t *testing.T) {
+			got, err := ParseCharset(tt.charset)
+			if tt.wantErr {
+				if !stderrors.Is(err, errors.ErrInvalidCharset) {
+					t.Errorf("Expected ErrInvalidCharset, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCharset returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected ramp %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCharsetNames(t *testing.T) {
+	names := CharsetNames()
+	if len(names) != len(Charsets) {
+		t.Fatalf("Expected %d names, got %d", len(Charsets), len(names))
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Errorf("Expected sorted names, got %v", names)
+		}
+	}
+}
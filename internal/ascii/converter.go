@@ -5,9 +5,10 @@ import (
 	"image"
 	"image/color"
 	"math"
-	"strings"
 
 	"github.com/muesli/termenv"
+
+	"github.com/muesli/asciicam/internal/screen"
 )
 
 // Converter handles the conversion of images to ASCII/ANSI art.
@@ -16,13 +17,25 @@ type Converter struct {
 	pixels []rune
 	// globalColor is the global color to use for ASCII output (if set)
 	globalColor color.Color
+	// invert maps dark pixels to the glyphs with the most ink, for terminals
+	// with a light background
+	invert bool
+	// edgeThreshold enables edge mode in ImageToASCII when above 0: edges
+	// at least this strong are drawn over a faded image
+	edgeThreshold float64
+	// brailleDither raises braille dots by ordered dithering instead of
+	// comparing them to the rest of their cell
+	brailleDither bool
+	// dither hides the banding of mapping pixels to few glyphs or colors
+	dither DitherMode
 }
 
 // NewConverter creates a new ASCII converter with default settings.
 func NewConverter() *Converter {
 	return &Converter{
-		pixels:      []rune{' ', '.', ',', ':', ';', 'i', '1', 't', 'f', 'L', 'C', 'G', '0', '8', '@'},
+		pixels:      []rune(Charsets[DefaultCharset]),
 		globalColor: color.Color(color.RGBA{0, 0, 0, 0}), // alpha 0 means use truecolor
+		dither:      DitherNone,
 	}
 }
 
@@ -31,6 +44,36 @@ func (c *Converter) SetGlobalColor(col color.Color) {
 	c.globalColor = col
 }
 
+// SetCharset replaces the character ramp. ramp runs from the glyph with the
+// least ink to the one with the most and needs at least two glyphs; see
+// Charsets for presets.
+func (c *Converter) SetCharset(ramp string) error {
+	if err := validateRamp(ramp); err != nil {
+		return err
+	}
+	c.pixels = []rune(ramp)
+	return nil
+}
+
+// SetInvert makes dark pixels use the glyphs with the most ink, which suits
+// terminals with a light background.
+func (c *Converter) SetInvert(invert bool) {
+	c.invert = invert
+}
+
+// SetEdges makes ImageToASCII draw the edges in images as lines of -, /, |
+// and \ over a faded version of the image. threshold is the strength edges
+// need, where 1 is a sharp step from black to white; 0 turns edges off.
+func (c *Converter) SetEdges(threshold float64) {
+	c.edgeThreshold = threshold
+}
+
+// SetDither selects how glyphs in ASCII mode, and colors when the profile
+// has a limited palette, are dithered.
+func (c *Converter) SetDither(mode DitherMode) {
+	c.dither = mode
+}
+
 // pixelToASCII converts a color pixel to an ASCII character based on its intensity.
 // Darker pixels are represented by characters with less "ink" (like spaces or dots),
 // while brighter pixels use more "ink-heavy" characters (like @ or 8).
@@ -47,19 +90,51 @@ func (c *Converter) pixelToASCII(pixel color.Color) rune {
 	// Calculate intensity, taking alpha into account
 	intensity := (r + g + b) * a / 255
 
+	glyph, _ := c.rampGlyph(float64(intensity))
+	return glyph
+}
+
+// rampStep returns the intensity difference between neighboring glyphs of
+// the ramp, on the 0 to 765 scale of pixelToASCII.
+func (c *Converter) rampStep() float64 {
 	// Calculate precision based on number of available ASCII characters
-	precision := float64(255 * 3 / (len(c.pixels) - 1))
+	return 255 * 3 / float64(len(c.pixels)-1)
+}
 
-	// Map intensity to an index in the pixels array
-	v := int(math.Floor(float64(intensity)/precision + 0.5))
-	return c.pixels[v]
+// rampGlyph returns the glyph for intensity, from 0 to 765, along with the
+// intensity the glyph stands for.
+func (c *Converter) rampGlyph(intensity float64) (rune, float64) {
+	last := len(c.pixels) - 1
+	precision := c.rampStep()
+
+	// Map intensity to an index in the pixels array, within its bounds as
+	// dithering can push intensities past black and white.
+	v := max(0, min(int(math.Floor(intensity/precision+0.5)), last))
+	level := float64(v) * precision
+	if c.invert {
+		v = last - v
+	}
+	return c.pixels[v], level
+}
+
+// ditheredGlyph returns the glyph for pixel x, y, dithering its intensity
+// with d.
+func (c *Converter) ditheredGlyph(d *ditherer, x, y int, pixel color.NRGBA) rune {
+	intensity := float64(int(pixel.R)+int(pixel.G)+int(pixel.B)) * float64(pixel.A) / 255
+	adjusted := d.adjust(x, y, 0, intensity, c.rampStep(), 255*3)
+	glyph, level := c.rampGlyph(adjusted)
+	d.diffuse(x, 0, adjusted-level)
+	return glyph
 }
 
 // ImageToASCII converts an image to ASCII art with color.
 // Each pixel is represented by an ASCII character with the appropriate color.
 func (c *Converter) ImageToASCII(width, height uint, p termenv.Profile, img image.Image) string {
-	str := strings.Builder{}
+	return c.ImageToASCIIScreen(width, height, p, img).String()
+}
 
+// ImageToASCIIScreen is ImageToASCII drawing into a screen buffer.
+func (c *Converter) ImageToASCIIScreen(width, height uint, p termenv.Profile, img image.Image) *screen.Buffer {
 	// Safe conversion with bounds checking
 	const maxInt = int(^uint(0) >> 1)
 	safeHeight := int(height)
@@ -70,28 +145,51 @@ func (c *Converter) ImageToASCII(width, height uint, p termenv.Profile, img imag
 	if width > uint(maxInt) {
 		safeWidth = maxInt
 	}
+	buf := screen.NewBuffer(safeWidth, safeHeight)
+
+	var edges *EdgeMap
+	if c.edgeThreshold > 0 {
+		edges = DetectEdges(img, c.edgeThreshold)
+	}
+	glyphs := newDitherer(c.dither, safeWidth, 1)
+	colors := c.newPaletteDither(p, safeWidth)
 
 	for i := 0; i < safeHeight; i++ {
 		for j := 0; j < safeWidth; j++ {
 			// Get pixel and convert to ASCII character
-			pixel := color.NRGBAModel.Convert(img.At(j, i))
-			s := termenv.String(string(c.pixelToASCII(pixel)))
-
-			// Apply color - either the global color (if set) or the pixel's color
-			_, _, _, a := c.globalColor.RGBA()
-			if a > 0 {
-				// Use global color if it has been set
-				s = s.Foreground(p.FromColor(c.globalColor))
-			} else {
-				// Otherwise use the pixel's color
-				s = s.Foreground(p.FromColor(pixel))
+			pixel := color.NRGBAModel.Convert(img.At(j, i)).(color.NRGBA)
+			glyph := edges.At(j, i)
+			if glyph == 0 {
+				if edges != nil {
+					pixel = c.fade(pixel, edgeFade)
+				}
+				if glyphs != nil {
+					glyph = c.ditheredGlyph(glyphs, j, i, pixel)
+				} else {
+					glyph = c.pixelToASCII(pixel)
+				}
 			}
-			str.WriteString(s.String())
+			buf.Set(j, i, screen.Cell{Glyph: glyph, FG: c.foreground(p, colors.quantize(j, i, pixel))})
 		}
-		str.WriteString("\n") // End of row
+		if glyphs != nil {
+			glyphs.nextRow()
+		}
+		colors.nextRow()
 	}
 
-	return str.String()
+	return buf
+}
+
+// foreground returns the foreground color for a cell: either the global
+// color (if set) or the color of the pixels the cell stands for.
+func (c *Converter) foreground(p termenv.Profile, pixel color.Color) termenv.Color {
+	_, _, _, a := c.globalColor.RGBA()
+	if a > 0 {
+		// Use global color if it has been set
+		return p.FromColor(c.globalColor)
+	}
+	// Otherwise use the pixel's color
+	return p.FromColor(pixel)
 }
 
 // ImageToANSI converts an image to colored ANSI blocks.
@@ -99,21 +197,27 @@ func (c *Converter) ImageToASCII(width, height uint, p termenv.Profile, img imag
 // colors to represent two pixels vertically in a single character position.
 // This provides higher vertical resolution than ASCII art.
 func (c *Converter) ImageToANSI(p termenv.Profile, img image.Image) string {
+	return c.ImageToANSIScreen(p, img).String()
+}
+
+// ImageToANSIScreen is ImageToANSI drawing into a screen buffer.
+func (c *Converter) ImageToANSIScreen(p termenv.Profile, img image.Image) *screen.Buffer {
+	img = c.ditherPalette(p, img)
 	b := img.Bounds()
+	buf := screen.NewBuffer(b.Max.X, (b.Max.Y+1)/2)
 
-	str := strings.Builder{}
 	for y := 0; y < b.Max.Y; y += 2 {
 		for x := 0; x < b.Max.X; x++ {
 			// Use the upper half block character (▀)
 			// The foreground color is the top pixel
 			// The background color is the bottom pixel
-			str.WriteString(termenv.String("▀").
-				Foreground(p.FromColor(img.At(x, y))).
-				Background(p.FromColor(img.At(x, y+1))).
-				String())
+			buf.Set(x, y/2, screen.Cell{
+				Glyph: '▀',
+				FG:    p.FromColor(img.At(x, y)),
+				BG:    p.FromColor(img.At(x, y+1)),
+			})
 		}
-		str.WriteString("\n") // End of row
 	}
 
-	return str.String()
+	return buf
 }
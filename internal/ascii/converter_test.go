@@ -25,6 +25,67 @@ func TestNewConverter(t *testing.T) {
 	}
 }
 
+func TestSetCharset(t *testing.T) {
+	converter := NewConverter()
+	if err := converter.SetCharset("ab"); err != nil {
+		t.Fatalf("SetCharset returned error: %v", err)
+	}
+
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	if got := converter.pixelToASCII(black); got != 'a' {
+		t.Errorf("Expected 'a' for black, got %q", got)
+	}
+	if got := converter.pixelToASCII(white); got != 'b' {
+		t.Errorf("Expected 'b' for white, got %q", got)
+	}
+
+	// A ramp too short is rejected and the previous one kept
+	if err := converter.SetCharset("x"); err == nil {
+		t.Error("Expected error for single glyph ramp, got none")
+	}
+	if got := converter.pixelToASCII(black); got != 'a' {
+		t.Errorf("Expected previous ramp to stay, got %q", got)
+	}
+}
+
+func TestSetCharset_LongRamp(t *testing.T) {
+	converter := NewConverter()
+	if err := converter.SetCharset(Charsets["standard"]); err != nil {
+		t.Fatalf("SetCharset returned error: %v", err)
+	}
+
+	// The brightest pixels map to the last glyph instead of past it
+	if got := converter.pixelToASCII(color.RGBA{255, 255, 255, 255}); got != '$' {
+		t.Errorf("Expected '$' for white, got %q", got)
+	}
+	if got := converter.pixelToASCII(color.RGBA{0, 0, 0, 255}); got != ' ' {
+		t.Errorf("Expected ' ' for black, got %q", got)
+	}
+
+	// Ramps with more glyphs than intensities work as well
+	if err := converter.SetCharset(strings.Repeat("ab", 400)); err != nil {
+		t.Fatalf("SetCharset returned error: %v", err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(1, 0, color.RGBA{255, 255, 255, 255})
+	if got := converter.ImageToASCII(2, 1, termenv.Ascii, img); got != "ab\n" {
+		t.Errorf("Expected \"ab\\n\", got %q", got)
+	}
+}
+
+func TestSetInvert(t *testing.T) {
+	converter := NewConverter()
+	converter.SetInvert(true)
+
+	if got := converter.pixelToASCII(color.RGBA{0, 0, 0, 255}); got != '@' {
+		t.Errorf("Expected '@' for black when inverted, got %q", got)
+	}
+	if got := converter.pixelToASCII(color.RGBA{255, 255, 255, 255}); got != ' ' {
+		t.Errorf("Expected ' ' for white when inverted, got %q", got)
+	}
+}
+
 func TestSetGlobalColor(t *testing.T) {
 	converter := NewConverter()
 	testColor := color.RGBA{255, 0, 0, 255} // Red
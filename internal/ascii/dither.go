@@ -0,0 +1,361 @@
+package ascii
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/muesli/termenv"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// DitherMode selects how quantization error is hidden when pixels are mapped
+// to the few glyphs of a ramp or the colors of a limited palette.
+type DitherMode string
+
+// Dither modes. The ordered modes add a fixed threshold pattern, so still
+// parts of a video do not shimmer; error diffusion is finer but changes with
+// any pixel before it.
+const (
+	DitherNone DitherMode = "none"
+	// Ordered dithering with Bayer matrices of 2x2, 4x4 and 8x8 thresholds
+	DitherBayer2 DitherMode = "bayer2"
+	DitherBayer4 DitherMode = "bayer4"
+	DitherBayer8 DitherMode = "bayer8"
+	// Error diffusion spreading all of the error to the neighbors ahead
+	DitherFloydSteinberg DitherMode = "floyd-steinberg"
+	// Error diffusion spreading three quarters of the error, for more contrast
+	DitherAtkinson DitherMode = "atkinson"
+	// Ordered dithering with a blue noise pattern, which has no visible grid
+	DitherBlueNoise DitherMode = "blue-noise"
+)
+
+// DitherModes lists the dither modes.
+var DitherModes = []DitherMode{
+	DitherNone, DitherBayer2, DitherBayer4, DitherBayer8,
+	DitherFloydSteinberg, DitherAtkinson, DitherBlueNoise,
+}
+
+// ParseDitherMode checks that mode names a dither mode.
+func ParseDitherMode(mode string) (DitherMode, error) {
+	names := make([]string, len(DitherModes))
+	for i, m := range DitherModes {
+		if string(m) == mode {
+			return m, nil
+		}
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("%w: unknown dither mode %q, want one of %s", errors.ErrInvalidConfig, mode, strings.Join(names, ", "))
+}
+
+// thresholdMap is a square tile of thresholds between 0 and 1, repeated over
+// the image by ordered dithering.
+type thresholdMap struct {
+	size       int
+	thresholds []float64
+}
+
+// at returns the threshold for pixel x, y.
+func (m *thresholdMap) at(x, y int) float64 {
+	x, y = ((x%m.size)+m.size)%m.size, ((y%m.size)+m.size)%m.size
+	return m.thresholds[y*m.size+x]
+}
+
+// newRankMap returns the threshold map for the ranks of size x size pixels,
+// which run from 0 to size*size-1 in row-major order.
+func newRankMap(size int, ranks []int) *thresholdMap {
+	m := &thresholdMap{size: size, thresholds: make([]float64, len(ranks))}
+	for i, r := range ranks {
+		m.thresholds[i] = (float64(r) + 0.5) / float64(len(ranks))
+	}
+	return m
+}
+
+// bayerMatrix returns the size x size Bayer matrix, size being a power of two.
+func bayerMatrix(size int) *thresholdMap {
+	ranks := []int{0}
+	for s := 1; s < size; s *= 2 {
+		// Each step repeats the matrix in four quadrants, offset by 0 and 2
+		// on top and 3 and 1 below
+		next := make([]int, 4*s*s)
+		for y := 0; y < 2*s; y++ {
+			for x := 0; x < 2*s; x++ {
+				offset := [2][2]int{{0, 2}, {3, 1}}[y/s][x/s]
+				next[y*2*s+x] = 4*ranks[(y%s)*s+x%s] + offset
+			}
+		}
+		ranks = next
+	}
+	return newRankMap(size, ranks)
+}
+
+var (
+	bayer2 = sync.OnceValue(func() *thresholdMap { return bayerMatrix(2) })
+	bayer4 = sync.OnceValue(func() *thresholdMap { return bayerMatrix(4) })
+	bayer8 = sync.OnceValue(func() *thresholdMap { return bayerMatrix(8) })
+
+	// blueNoise is generated once, as that takes a few milliseconds
+	blueNoise = sync.OnceValue(func() *thresholdMap { return blueNoiseMatrix(32, 1.5) })
+)
+
+// blueNoiseMatrix returns a size x size blue noise threshold map made with
+// the void-and-cluster method: points are ranked by repeatedly taking the
+// most crowded one away or filling the emptiest gap, measuring crowding with
+// a Gaussian of width sigma that wraps around the tile.
+func blueNoiseMatrix(size int, sigma float64) *thresholdMap {
+	n := size * size
+	weights := make([]float64, n)
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size; dx++ {
+			wx, wy := float64(min(dx, size-dx)), float64(min(dy, size-dy))
+			weights[dy*size+dx] = math.Exp(-(wx*wx + wy*wy) / (2 * sigma * sigma))
+		}
+	}
+
+	// energy[i] is how crowded the points make pixel i
+	pattern := make([]bool, n)
+	energy := make([]float64, n)
+	toggle := func(i int, on bool) {
+		pattern[i] = on
+		sign := 1.0
+		if !on {
+			sign = -1
+		}
+		ix, iy := i%size, i/size
+		for j := range energy {
+			dx, dy := (j%size-ix+size)%size, (j/size-iy+size)%size
+			energy[j] += sign * weights[dy*size+dx]
+		}
+	}
+	// extreme returns the most crowded point or the emptiest gap
+	extreme := func(on bool) int {
+		best := -1
+		for i, p := range pattern {
+			if p != on {
+				continue
+			}
+			if best < 0 || on && energy[i] > energy[best] || !on && energy[i] < energy[best] {
+				best = i
+			}
+		}
+		return best
+	}
+
+	// Start from a tenth of the points at random, then move the most crowded
+	// point to the emptiest gap until that changes nothing
+	rng := rand.New(rand.NewSource(1))
+	initial := n / 10
+	for placed := 0; placed < initial; {
+		if i := rng.Intn(n); !pattern[i] {
+			toggle(i, true)
+			placed++
+		}
+	}
+	for {
+		cluster := extreme(true)
+		toggle(cluster, false)
+		void := extreme(false)
+		toggle(void, true)
+		if void == cluster {
+			break
+		}
+	}
+	start := append([]bool(nil), pattern...)
+	startEnergy := append([]float64(nil), energy...)
+
+	ranks := make([]int, n)
+	// The initial points rank below it, most crowded last
+	for rank := initial - 1; rank >= 0; rank-- {
+		i := extreme(true)
+		toggle(i, false)
+		ranks[i] = rank
+	}
+	// The others rank above it, emptiest gap first
+	copy(pattern, start)
+	copy(energy, startEnergy)
+	for rank := initial; rank < n; rank++ {
+		i := extreme(false)
+		toggle(i, true)
+		ranks[i] = rank
+	}
+	return newRankMap(size, ranks)
+}
+
+// thresholdMap returns the threshold pattern of ordered modes, or nil for
+// the others.
+func (m DitherMode) thresholdMap() *thresholdMap {
+	switch m {
+	case DitherBayer2:
+		return bayer2()
+	case DitherBayer4:
+		return bayer4()
+	case DitherBayer8:
+		return bayer8()
+	case DitherBlueNoise:
+		return blueNoise()
+	}
+	return nil
+}
+
+// diffusion is a share of the error passed on to the pixel dx, dy away.
+type diffusion struct {
+	dx, dy int
+	weight float64
+}
+
+// diffusionKernel returns where error diffusion modes pass the error on to,
+// or nil for the others.
+func (m DitherMode) diffusionKernel() []diffusion {
+	switch m {
+	case DitherFloydSteinberg:
+		return []diffusion{{1, 0, 7.0 / 16}, {-1, 1, 3.0 / 16}, {0, 1, 5.0 / 16}, {1, 1, 1.0 / 16}}
+	case DitherAtkinson:
+		return []diffusion{{1, 0, 1.0 / 8}, {2, 0, 1.0 / 8}, {-1, 1, 1.0 / 8}, {0, 1, 1.0 / 8}, {1, 1, 1.0 / 8}, {0, 2, 1.0 / 8}}
+	}
+	return nil
+}
+
+// ditherer dithers the values of an image with one or more channels, which
+// are quantized left to right and top to bottom.
+type ditherer struct {
+	thresholds *thresholdMap
+	kernel     []diffusion
+	width      int
+	channels   int
+	errs       [3][]float64 // error passed on to this row and the next two
+}
+
+// newDitherer returns a ditherer for rows of width pixels, or nil if mode
+// does not dither.
+func newDitherer(mode DitherMode, width, channels int) *ditherer {
+	d := &ditherer{
+		thresholds: mode.thresholdMap(),
+		kernel:     mode.diffusionKernel(),
+		width:      width,
+		channels:   channels,
+	}
+	if d.thresholds == nil && d.kernel == nil {
+		return nil
+	}
+	for i := range d.errs {
+		d.errs[i] = make([]float64, width*channels)
+	}
+	return d
+}
+
+// adjust returns value v of channel ch of pixel x, y with dithering applied,
+// clamped between 0 and limit. step is the distance between the levels v is
+// quantized to.
+func (d *ditherer) adjust(x, y, ch int, v, step, limit float64) float64 {
+	if d.thresholds != nil {
+		v += (d.thresholds.at(x, y) - 0.5) * step
+	} else if x >= 0 && x < d.width {
+		v += d.errs[0][x*d.channels+ch]
+	}
+	return math.Max(0, math.Min(v, limit))
+}
+
+// diffuse passes on err, the difference between an adjusted value of pixel x
+// and the level it was quantized to.
+func (d *ditherer) diffuse(x, ch int, err float64) {
+	for _, k := range d.kernel {
+		if nx := x + k.dx; nx >= 0 && nx < d.width {
+			d.errs[k.dy][nx*d.channels+ch] += err * k.weight
+		}
+	}
+}
+
+// nextRow moves on to the next row.
+func (d *ditherer) nextRow() {
+	if d.kernel == nil {
+		return
+	}
+	done := d.errs[0]
+	clear(done)
+	d.errs[0], d.errs[1], d.errs[2] = d.errs[1], d.errs[2], done
+}
+
+// paletteStep returns the typical distance between neighboring colors of the
+// palette of p in each channel, or 0 if p shows colors as they are.
+func paletteStep(p termenv.Profile) float64 {
+	switch p {
+	case termenv.ANSI256:
+		// The 6x6x6 color cube
+		return 255.0 / 5
+	case termenv.ANSI:
+		return 255
+	}
+	return 0
+}
+
+// paletteDither reduces colors to the palette of a profile, dithering them.
+// A nil paletteDither leaves colors as they are.
+type paletteDither struct {
+	ditherer *ditherer
+	profile  termenv.Profile
+	step     float64
+}
+
+// newPaletteDither returns a paletteDither for rows of width pixels, or nil
+// if colors are not dithered for p.
+func (c *Converter) newPaletteDither(p termenv.Profile, width int) *paletteDither {
+	step := paletteStep(p)
+	d := newDitherer(c.dither, width, 3)
+	if step == 0 || d == nil {
+		return nil
+	}
+	return &paletteDither{ditherer: d, profile: p, step: step}
+}
+
+// quantize returns the palette color to show for pixel x, y of color col.
+func (pd *paletteDither) quantize(x, y int, col color.Color) color.Color {
+	if pd == nil {
+		return col
+	}
+
+	// Palettes hold opaque colors, so the channels are dithered without alpha
+	px := color.NRGBAModel.Convert(col).(color.NRGBA)
+	in := [3]float64{float64(px.R), float64(px.G), float64(px.B)}
+	var adjusted [3]uint8
+	for ch, v := range in {
+		adjusted[ch] = uint8(math.Round(pd.ditherer.adjust(x, y, ch, v, pd.step, 255)))
+	}
+
+	out := termenv.ConvertToRGB(pd.profile.FromColor(color.RGBA{adjusted[0], adjusted[1], adjusted[2], 255}))
+	levels := [3]float64{out.R * 255, out.G * 255, out.B * 255}
+	for ch := range adjusted {
+		pd.ditherer.diffuse(x, ch, float64(adjusted[ch])-levels[ch])
+	}
+	return color.NRGBA{uint8(math.Round(levels[0])), uint8(math.Round(levels[1])), uint8(math.Round(levels[2])), px.A}
+}
+
+// nextRow moves on to the next row.
+func (pd *paletteDither) nextRow() {
+	if pd != nil {
+		pd.ditherer.nextRow()
+	}
+}
+
+// ditherPalette returns img with its colors reduced to the palette of p by
+// dithering, or img itself if colors are not dithered for p.
+func (c *Converter) ditherPalette(p termenv.Profile, img image.Image) image.Image {
+	b := img.Bounds()
+	pd := c.newPaletteDither(p, b.Dx())
+	if pd == nil {
+		return img
+	}
+
+	out := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			out.Set(x, y, pd.quantize(x-b.Min.X, y, img.At(x, y)))
+		}
+		pd.nextRow()
+	}
+	return out
+}
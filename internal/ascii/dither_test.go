@@ -0,0 +1,213 @@
+package ascii
+
+import (
+	stderrors "errors"
+	"image"
+	"image/color"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestParseDitherMode(t *testing.T) {
+	for _, mode := range DitherModes {
+		if got, err := ParseDitherMode(string(mode)); err != nil || got != mode {
+			t.Errorf("ParseDitherMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+
+	if _, err := ParseDitherMode("random"); !stderrors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestBayerMatrix(t *testing.T) {
+	want := [][]float64{
+		{0, 8, 2, 10},
+		{12, 4, 14, 6},
+		{3, 11, 1, 9},
+		{15, 7, 13, 5},
+	}
+
+	m := bayer4()
+	for y, row := range want {
+		for x, rank := range row {
+			if got := m.at(x, y); got != (rank+0.5)/16 {
+				t.Errorf("Expected threshold %g at %d,%d, got %g", (rank+0.5)/16, x, y, got)
+			}
+		}
+	}
+
+	// The matrix repeats, also towards negative coordinates
+	if m.at(-1, -4) != m.at(3, 0) {
+		t.Error("Expected the matrix to repeat")
+	}
+}
+
+func TestThresholdMaps(t *testing.T) {
+	for _, mode := range []DitherMode{DitherBayer2, DitherBayer4, DitherBayer8, DitherBlueNoise} {
+		m := mode.thresholdMap()
+
+		// Every threshold is used once
+		seen := make(map[float64]bool)
+		for _, v := range m.thresholds {
+			if seen[v] || v <= 0 || v >= 1 {
+				t.Errorf("%s: unexpected threshold %g", mode, v)
+			}
+			seen[v] = true
+		}
+		if len(seen) != m.size*m.size {
+			t.Errorf("%s: expected %d thresholds, got %d", mode, m.size*m.size, len(seen))
+		}
+	}
+}
+
+func TestBlueNoise_Spread(t *testing.T) {
+	m := blueNoise()
+
+	// The lowest thresholds are spread out instead of clumping together
+	var points []image.Point
+	for i, v := range m.thresholds {
+		if v < 1.0/16 {
+			points = append(points, image.Pt(i%m.size, i/m.size))
+		}
+	}
+	for i, a := range points {
+		for _, b := range points[i+1:] {
+			dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+			dx, dy = min(dx, m.size-dx), min(dy, m.size-dy)
+			if dx <= 1 && dy <= 1 {
+				t.Fatalf("Expected no neighboring points among the lowest thresholds, got %v and %v", a, b)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestDiffusionKernels(t *testing.T) {
+	tests := []struct {
+		mode DitherMode
+		want float64
+	}{
+		{DitherFloydSteinberg, 1},
+		{DitherAtkinson, 0.75},
+	}
+
+	for _, tt := range tests {
+		var sum float64
+		for _, k := range tt.mode.diffusionKernel() {
+			sum += k.weight
+			if k.dy < 0 || k.dy > 2 || k.dy == 0 && k.dx <= 0 {
+				t.Errorf("%s: error passed back to %d,%d", tt.mode, k.dx, k.dy)
+			}
+		}
+		if sum != tt.want {
+			t.Errorf("%s: expected %g of the error passed on, got %g", tt.mode, tt.want, sum)
+		}
+	}
+}
+
+func TestImageToASCII_Dither(t *testing.T) {
+	// A quarter bright gray only has glyphs on a two glyph ramp when dithered
+	img := grayImage(16, 16, 64)
+
+	tests := []struct {
+		mode      DitherMode
+		min, max  int
+		wantExact bool
+	}{
+		{DitherNone, 0, 0, true},
+		{DitherBayer2, 64, 64, true},
+		{DitherBayer4, 64, 64, true},
+		{DitherBayer8, 64, 64, true},
+		{DitherBlueNoise, 56, 72, false},
+		{DitherFloydSteinberg, 56, 72, false},
+		{DitherAtkinson, 0, 72, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			converter := NewConverter()
+			if err := converter.SetCharset(" #"); err != nil {
+				t.Fatal(err)
+			}
+			converter.SetDither(tt.mode)
+
+			got := converter.ImageToASCII(16, 16, termenv.Ascii, img)
+			n := strings.Count(got, "#")
+			if n < tt.min || n > tt.max {
+				t.Errorf("Expected %d to %d of 256 glyphs set, got %d:\n%s", tt.min, tt.max, n, got)
+			}
+
+			// Ordered dithering gives the same output for the same frame
+			if again := converter.ImageToASCII(16, 16, termenv.Ascii, img); again != got {
+				t.Error("Expected the same output for the same frame")
+			}
+		})
+	}
+}
+
+// sgrColors matches the color codes of SGR sequences.
+var sgrColors = regexp.MustCompile(`\x1b\[([0-9;]+)m`)
+
+// distinctColors returns the number of distinct color sequences in s.
+func distinctColors(s string) int {
+	seen := make(map[string]bool)
+	for _, m := range sgrColors.FindAllStringSubmatch(s, -1) {
+		if m[1] != "0" {
+			seen[m[1]] = true
+		}
+	}
+	return len(seen)
+}
+
+func TestImageToANSI_DitherPalette(t *testing.T) {
+	// A gray halfway between two colors of the 16 color palette
+	img := grayImage(8, 8, 160)
+
+	converter := NewConverter()
+	if n := distinctColors(converter.ImageToANSI(termenv.ANSI, img)); n != 1 {
+		t.Errorf("Expected a single color without dithering, got %d", n)
+	}
+
+	converter.SetDither(DitherBayer4)
+	if n := distinctColors(converter.ImageToANSI(termenv.ANSI, img)); n < 2 {
+		t.Errorf("Expected dithering to mix palette colors, got %d colors", n)
+	}
+
+	// True color needs no dithering
+	got := converter.ImageToANSI(termenv.TrueColor, img)
+	if n := distinctColors(got); n != 1 {
+		t.Errorf("Expected a single true color, got %d", n)
+	}
+}
+
+func TestDitherPalette_Colors(t *testing.T) {
+	converter := NewConverter()
+	converter.SetDither(DitherFloydSteinberg)
+
+	img := grayImage(4, 4, 200)
+	out := converter.ditherPalette(termenv.ANSI256, img)
+
+	// Every pixel becomes a palette color
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c := out.At(x, y)
+			rgb := termenv.ConvertToRGB(termenv.ANSI256.FromColor(c))
+			want := color.NRGBA{uint8(rgb.R*255 + 0.5), uint8(rgb.G*255 + 0.5), uint8(rgb.B*255 + 0.5), 255}
+			if c != want {
+				t.Errorf("Pixel %d,%d: %v is not a palette color", x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package ascii
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// edgeFade is how much of their contrast to the terminal background pixels
+// off the edges keep in edge mode, so the edges stand out from the image
+// behind them.
+const edgeFade = 0.4
+
+// EdgeMap holds the edges found in an image: for every pixel, the glyph that
+// draws the edge passing through it, or 0 if there is none.
+type EdgeMap struct {
+	Rect   image.Rectangle
+	glyphs []rune
+}
+
+// At returns the edge glyph at x, y, or 0 if no edge passes through it.
+func (m *EdgeMap) At(x, y int) rune {
+	if m == nil || !image.Pt(x, y).In(m.Rect) {
+		return 0
+	}
+	return m.glyphs[(y-m.Rect.Min.Y)*m.Rect.Dx()+(x-m.Rect.Min.X)]
+}
+
+// DetectEdges finds the edges in img with a Sobel filter. threshold is the
+// gradient strength an edge needs, where 1 is a sharp step from black to
+// white. Edges are thinned to a single pixel along their gradient and drawn
+// with -, /, | or \ depending on their direction in the image.
+func DetectEdges(img image.Image, threshold float64) *EdgeMap {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	m := &EdgeMap{Rect: b, glyphs: make([]rune, w*h)}
+	if w == 0 || h == 0 {
+		return m
+	}
+
+	lum := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			lum[y*w+x] = intensity(img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	// at clamps coordinates to the image, repeating its border
+	at := func(x, y int) float64 {
+		return lum[max(0, min(y, h-1))*w+max(0, min(x, w-1))]
+	}
+
+	gx := make([]float64, w*h)
+	gy := make([]float64, w*h)
+	mag := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			gx[i] = (at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)) / 4
+			gy[i] = (at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)) / 4
+			mag[i] = math.Hypot(gx[i], gy[i])
+		}
+	}
+	magAt := func(x, y int) float64 {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return 0
+		}
+		return mag[y*w+x]
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if mag[i] < threshold {
+				continue
+			}
+
+			// The direction of the edge is across the gradient, measured
+			// counterclockwise from the x axis with y pointing up
+			angle := math.Atan2(gx[i], gy[i]) * 180 / math.Pi
+			if angle < 0 {
+				angle += 180
+			}
+			glyph, dx, dy := edgeGlyph(angle)
+
+			// Keep only the strongest pixel across the edge. Diagonal steps
+			// skip a pixel, so diagonal edges are also compared sideways.
+			if mag[i] < magAt(x+dx, y+dy) || mag[i] <= magAt(x-dx, y-dy) {
+				continue
+			}
+			if dy != 0 && dx != 0 && (mag[i] < magAt(x+dx, y) || mag[i] <= magAt(x-dx, y)) {
+				continue
+			}
+			m.glyphs[i] = glyph
+		}
+	}
+	return m
+}
+
+// edgeGlyph returns the glyph for an edge running at angle degrees, from 0 to
+// 180, and the pixel offset that steps across it.
+func edgeGlyph(angle float64) (rune, int, int) {
+	switch {
+	case angle < 22.5 || angle >= 157.5:
+		return '-', 0, 1
+	case angle < 67.5:
+		return '/', 1, 1
+	case angle < 112.5:
+		return '|', 1, 0
+	default:
+		return '\\', 1, -1
+	}
+}
+
+// intensity returns the brightness of c from 0 to 1, taking alpha into
+// account as pixelToASCII does.
+func intensity(c color.Color) float64 {
+	// The channels are premultiplied with alpha already
+	r, g, b, _ := c.RGBA()
+	return float64(r+g+b) / (3 * 0xffff)
+}
+
+// fade moves pixel towards the terminal background, black or white if the
+// ramp is inverted, keeping the fraction f of its distance.
+func (c *Converter) fade(pixel color.NRGBA, f float64) color.NRGBA {
+	bg := 0.0
+	if c.invert {
+		bg = 255
+	}
+	pixel.R = uint8(bg + (float64(pixel.R)-bg)*f)
+	pixel.G = uint8(bg + (float64(pixel.G)-bg)*f)
+	pixel.B = uint8(bg + (float64(pixel.B)-bg)*f)
+	return pixel
+}
@@ -0,0 +1,123 @@
+package ascii
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+// stepImage returns a size x size image that is white where bright returns
+// true and black elsewhere.
+func stepImage(size int, bright func(x, y int) bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if bright(x, y) {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+// edgeGlyphs returns the edge glyphs of m by row, with spaces for no edge.
+func edgeGlyphs(m *EdgeMap) []string {
+	var rows []string
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		var row strings.Builder
+		for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+			if g := m.At(x, y); g != 0 {
+				row.WriteRune(g)
+			} else {
+				row.WriteRune(' ')
+			}
+		}
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
+func TestDetectEdges(t *testing.T) {
+	tests := []struct {
+		name   string
+		bright func(x, y int) bool
+		want   rune
+	}{
+		{"vertical", func(x, y int) bool { return x >= 4 }, '|'},
+		{"horizontal", func(x, y int) bool { return y >= 4 }, '-'},
+		{"rising", func(x, y int) bool { return x+y >= 8 }, '/'},
+		{"falling", func(x, y int) bool { return x >= y }, '\\'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := edgeGlyphs(DetectEdges(stepImage(8, tt.bright), 0.2))
+
+			var count int
+			for _, row := range rows {
+				for _, g := range row {
+					if g != ' ' && g != tt.want {
+						t.Fatalf("Expected only %q edges, got\n%s", tt.want, strings.Join(rows, "\n"))
+					}
+					if g == tt.want {
+						count++
+					}
+				}
+			}
+			// Edges are thinned to one pixel across
+			if count < 6 || count > 8 {
+				t.Errorf("Expected a single line of %q edges, got\n%s", tt.want, strings.Join(rows, "\n"))
+			}
+		})
+	}
+}
+
+func TestDetectEdges_Threshold(t *testing.T) {
+	// A step from black to mid gray is too weak for a high threshold
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 4; x < 8; x++ {
+			img.SetGray(x, y, color.Gray{Y: 100})
+		}
+	}
+
+	if rows := edgeGlyphs(DetectEdges(img, 0.5)); strings.TrimSpace(strings.Join(rows, "")) != "" {
+		t.Errorf("Expected no edges, got\n%s", strings.Join(rows, "\n"))
+	}
+	if rows := edgeGlyphs(DetectEdges(img, 0.2)); !strings.Contains(strings.Join(rows, ""), "|") {
+		t.Errorf("Expected a vertical edge, got\n%s", strings.Join(rows, "\n"))
+	}
+}
+
+func TestEdgeMap_At(t *testing.T) {
+	var m *EdgeMap
+	if g := m.At(0, 0); g != 0 {
+		t.Errorf("Expected no edge in nil map, got %q", g)
+	}
+
+	m = DetectEdges(stepImage(4, func(x, y int) bool { return x >= 2 }), 0.2)
+	if g := m.At(-1, 0); g != 0 {
+		t.Errorf("Expected no edge outside the image, got %q", g)
+	}
+	if g := m.At(1, 1); g != '|' {
+		t.Errorf("Expected vertical edge at 1,1, got %q", g)
+	}
+}
+
+func TestImageToASCII_Edges(t *testing.T) {
+	img := stepImage(8, func(x, y int) bool { return x >= 4 })
+
+	converter := NewConverter()
+	converter.SetEdges(0.2)
+	got := converter.ImageToASCII(8, 8, termenv.Ascii, img)
+
+	// The edge is drawn between the halves, and the bright half is faded
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if line != "   |1111" {
+			t.Fatalf("Expected edge over faded image, got\n%s", got)
+		}
+	}
+}
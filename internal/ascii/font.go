@@ -0,0 +1,162 @@
+package ascii
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// glyphRenderSize is the size in pixels glyphs are rasterized at to measure
+// their coverage. Larger sizes average out hinting and antialiasing noise.
+const glyphRenderSize = 64
+
+// GlyphCoverage is the fraction of a character cell covered by a glyph's ink,
+// from 0 for a blank cell to 1 for a solid block.
+type GlyphCoverage struct {
+	Rune     rune
+	Coverage float64
+}
+
+// MeasureGlyphs rasterizes chars with the TrueType or OpenType font in
+// fontData and returns how much of a cell each of them covers, sorted from
+// the least to the most ink. Characters the font has no glyph for are left
+// out, as are repeated ones.
+func MeasureGlyphs(fontData []byte, chars string) ([]GlyphCoverage, error) {
+	var glyphs []GlyphCoverage
+	err := rasterizeGlyphs(fontData, chars, func(r rune, cell *image.Alpha) {
+		glyphs = append(glyphs, GlyphCoverage{
+			Rune:     r,
+			Coverage: alphaCoverage(cell, cell.Rect),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(glyphs, func(i, j int) bool {
+		return glyphs[i].Coverage < glyphs[j].Coverage
+	})
+	return glyphs, nil
+}
+
+// rasterizeGlyphs draws each distinct character of chars the font in fontData
+// has a glyph for into a cell and passes it to fn. The cell is reused for the
+// next glyph once fn returns.
+func rasterizeGlyphs(fontData []byte, chars string, fn func(r rune, cell *image.Alpha)) error {
+	f, err := opentype.Parse(fontData)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidFont, err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    glyphRenderSize,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidFont, err)
+	}
+	defer face.Close()
+
+	// Every glyph is drawn into the same cell: as tall as the line and as
+	// wide as the widest glyph, which for monospaced fonts is all of them.
+	var runes []rune
+	var cellWidth fixed.Int26_6
+	seen := make(map[rune]bool)
+	for _, r := range chars {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+
+		if idx, _ := f.GlyphIndex(nil, r); idx == 0 {
+			continue
+		}
+		advance, ok := face.GlyphAdvance(r)
+		if !ok {
+			continue
+		}
+		runes = append(runes, r)
+		cellWidth = max(cellWidth, advance)
+	}
+
+	metrics := face.Metrics()
+	cell := image.NewAlpha(image.Rect(0, 0, cellWidth.Ceil(), metrics.Height.Ceil()))
+	if cell.Rect.Empty() {
+		return fmt.Errorf("%w: font has no glyphs for %q", errors.ErrInvalidFont, chars)
+	}
+
+	dot := fixed.Point26_6{Y: metrics.Ascent}
+	for _, r := range runes {
+		draw.Draw(cell, cell.Rect, image.Transparent, image.Point{}, draw.Src)
+		dr, mask, maskp, _, ok := face.Glyph(dot, r)
+		if ok {
+			draw.DrawMask(cell, dr, image.Opaque, image.Point{}, mask, maskp, draw.Over)
+		}
+		fn(r, cell)
+	}
+	return nil
+}
+
+// alphaCoverage returns the mean alpha of the part of img within r, from 0
+// for fully transparent to 1 for fully opaque.
+func alphaCoverage(img *image.Alpha, r image.Rectangle) float64 {
+	r = r.Intersect(img.Rect)
+	if r.Empty() {
+		return 0
+	}
+
+	var ink int
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(r.Min.X, y):img.PixOffset(r.Max.X, y)]
+		for _, a := range row {
+			ink += int(a)
+		}
+	}
+	return float64(ink) / float64(255*r.Dx()*r.Dy())
+}
+
+// RampFromGlyphs picks up to levels glyphs whose coverage is as evenly spaced
+// as possible between the emptiest and the fullest of glyphs, which must be
+// sorted as returned by MeasureGlyphs. All glyphs are used when there are no
+// more than levels of them.
+func RampFromGlyphs(glyphs []GlyphCoverage, levels int) (string, error) {
+	if levels < 2 {
+		return "", fmt.Errorf("%w: ramp needs at least two levels, got %d", errors.ErrInvalidCharset, levels)
+	}
+	if len(glyphs) < 2 {
+		return "", fmt.Errorf("%w: need at least two glyphs to build a ramp, got %d", errors.ErrInvalidCharset, len(glyphs))
+	}
+	levels = min(levels, len(glyphs))
+
+	lo, hi := glyphs[0].Coverage, glyphs[len(glyphs)-1].Coverage
+	ramp := make([]rune, 0, levels)
+	next := 0 // glyphs before next are used up or skipped
+	for i := 0; i < levels; i++ {
+		target := lo + (hi-lo)*float64(i)/float64(levels-1)
+
+		// Take the closest glyph that leaves enough for the levels still to
+		// come, keeping the ramp in order of coverage.
+		best := next
+		for j := next + 1; j <= len(glyphs)-(levels-i); j++ {
+			if math.Abs(glyphs[j].Coverage-target) < math.Abs(glyphs[best].Coverage-target) {
+				best = j
+			}
+		}
+		ramp = append(ramp, glyphs[best].Rune)
+		next = best + 1
+	}
+
+	r := string(ramp)
+	if err := validateRamp(r); err != nil {
+		return "", err
+	}
+	return r, nil
+}
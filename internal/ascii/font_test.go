@@ -0,0 +1,83 @@
+package ascii
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestMeasureGlyphs(t *testing.T) {
+	glyphs, err := MeasureGlyphs(gomono.TTF, "@.@ #\U0001F600")
+	if err != nil {
+		t.Fatalf("MeasureGlyphs failed: %v", err)
+	}
+
+	// The repeated @ and the emoji missing from the font are left out
+	var got string
+	for _, g := range glyphs {
+		got += string(g.Rune)
+	}
+	if got != " .#@" {
+		t.Fatalf("Expected glyphs %q, got %q", " .#@", got)
+	}
+
+	if glyphs[0].Coverage != 0 {
+		t.Errorf("Expected space to cover nothing, got %v", glyphs[0].Coverage)
+	}
+	for i := 1; i < len(glyphs); i++ {
+		if glyphs[i].Coverage <= glyphs[i-1].Coverage {
+			t.Errorf("Expected %q to cover more than %q", glyphs[i].Rune, glyphs[i-1].Rune)
+		}
+		if glyphs[i].Coverage > 1 {
+			t.Errorf("Expected coverage of %q to be at most 1, got %v", glyphs[i].Rune, glyphs[i].Coverage)
+		}
+	}
+}
+
+func TestMeasureGlyphs_InvalidFont(t *testing.T) {
+	_, err := MeasureGlyphs([]byte("not a font"), "ab")
+	if !stderrors.Is(err, errors.ErrInvalidFont) {
+		t.Errorf("Expected ErrInvalidFont, got %v", err)
+	}
+}
+
+func TestRampFromGlyphs(t *testing.T) {
+	glyphs := []GlyphCoverage{
+		{' ', 0}, {'.', 0.05}, {',', 0.06}, {':', 0.1}, {'o', 0.3},
+		{'O', 0.45}, {'8', 0.5}, {'#', 0.7}, {'@', 0.8}, {'M', 1},
+	}
+
+	tests := []struct {
+		levels  int
+		want    string
+		wantErr bool
+	}{
+		{2, " M", false},
+		{3, " 8M", false},
+		{5, " o8#M", false},
+		{10, " .,:oO8#@M", false},
+		{20, " .,:oO8#@M", false},
+		{1, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := RampFromGlyphs(glyphs, tt.levels)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RampFromGlyphs(%d) error = %v, wantErr %v", tt.levels, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RampFromGlyphs(%d) = %q, want %q", tt.levels, got, tt.want)
+		}
+	}
+}
+
+func TestRampFromGlyphs_TooFewGlyphs(t *testing.T) {
+	_, err := RampFromGlyphs([]GlyphCoverage{{' ', 0}}, 4)
+	if !stderrors.Is(err, errors.ErrInvalidCharset) {
+		t.Errorf("Expected ErrInvalidCharset, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package ascii
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/muesli/termenv"
+	"golang.org/x/image/font/gofont/gomono"
+
+	"github.com/muesli/asciicam/internal/screen"
+)
+
+// Shape matching samples every terminal cell as a grid of ShapeCellWidth x
+// ShapeCellHeight pixels. With cells twice as tall as wide, the samples are
+// square.
+const (
+	ShapeCellWidth  = 3
+	ShapeCellHeight = 6
+
+	shapeSamples = ShapeCellWidth * ShapeCellHeight
+)
+
+// shapeChars are the glyphs shape matching chooses from: printable ASCII.
+const shapeChars = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
+// shapeVector holds the brightness of a cell's samples in row-major order,
+// or the ink of a glyph in the matching parts of its cell, from 0 to 1.
+type shapeVector [shapeSamples]float64
+
+// shapeGlyph is a glyph along with the distribution of its ink.
+type shapeGlyph struct {
+	r     rune
+	shape shapeVector
+}
+
+// shapeGlyphs returns shapeChars rasterized with Go Mono, which is monospaced
+// and shaped like most terminal fonts. The ink of every part of the cell is
+// scaled so that the glyph with the most ink there reaches 1, letting a fully
+// bright patch match the densest glyphs.
+var shapeGlyphs = sync.OnceValue(func() []shapeGlyph {
+	var glyphs []shapeGlyph
+	err := rasterizeGlyphs(gomono.TTF, shapeChars, func(r rune, cell *image.Alpha) {
+		w, h := cell.Rect.Dx(), cell.Rect.Dy()
+		g := shapeGlyph{r: r}
+		for i := range g.shape {
+			x, y := i%ShapeCellWidth, i/ShapeCellWidth
+			part := image.Rect(x*w/ShapeCellWidth, y*h/ShapeCellHeight, (x+1)*w/ShapeCellWidth, (y+1)*h/ShapeCellHeight)
+			g.shape[i] = alphaCoverage(cell, part)
+		}
+		glyphs = append(glyphs, g)
+	})
+	if err != nil {
+		panic(fmt.Sprintf("rasterizing shape glyphs: %v", err))
+	}
+
+	var peak shapeVector
+	for _, g := range glyphs {
+		for i, v := range g.shape {
+			peak[i] = max(peak[i], v)
+		}
+	}
+	for gi := range glyphs {
+		for i, p := range peak {
+			if p > 0 {
+				glyphs[gi].shape[i] /= p
+			}
+		}
+	}
+	return glyphs
+})
+
+// matchShape returns the glyph whose ink is closest to shape.
+func matchShape(glyphs []shapeGlyph, shape *shapeVector) rune {
+	best, bestDist := ' ', -1.0
+	for i := range glyphs {
+		var dist float64
+		for j, v := range glyphs[i].shape {
+			d := v - shape[j]
+			dist += d * d
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = glyphs[i].r, dist
+		}
+	}
+	return best
+}
+
+// ImageToShapes converts an image to ASCII art that keeps its outlines. Each
+// cell covers ShapeCellWidth x ShapeCellHeight pixels and shows the glyph
+// whose shape best matches their brightness, so edges become strokes like
+// /, \, |, _ and ( instead of a glyph of similar ink. Cells are colored by
+// their mean color, unless a global color is set.
+func (c *Converter) ImageToShapes(p termenv.Profile, img image.Image) string {
+	return c.ImageToShapesScreen(p, img).String()
+}
+
+// ImageToShapesScreen is ImageToShapes drawing into a screen buffer.
+func (c *Converter) ImageToShapesScreen(p termenv.Profile, img image.Image) *screen.Buffer {
+	b := img.Bounds()
+	glyphs := shapeGlyphs()
+	colors := c.newPaletteDither(p, b.Dx()/ShapeCellWidth)
+	buf := screen.NewBuffer(b.Dx()/ShapeCellWidth, b.Dy()/ShapeCellHeight)
+
+	var shape shapeVector
+	for y := b.Min.Y; y+ShapeCellHeight <= b.Max.Y; y += ShapeCellHeight {
+		for x := b.Min.X; x+ShapeCellWidth <= b.Max.X; x += ShapeCellWidth {
+			var r, g, bl, a uint32
+			for i := range shape {
+				pixel := color.NRGBAModel.Convert(img.At(x+i%ShapeCellWidth, y+i/ShapeCellWidth)).(color.NRGBA)
+				r += uint32(pixel.R)
+				g += uint32(pixel.G)
+				bl += uint32(pixel.B)
+				a += uint32(pixel.A)
+
+				// Brightness, taking alpha into account, as in pixelToASCII
+				shape[i] = float64(int(pixel.R)+int(pixel.G)+int(pixel.B)) / (3 * 255) * float64(pixel.A) / 255
+				if c.invert {
+					shape[i] = 1 - shape[i]
+				}
+			}
+
+			mean := color.NRGBA{
+				R: uint8(r / shapeSamples),
+				G: uint8(g / shapeSamples),
+				B: uint8(bl / shapeSamples),
+				A: uint8(a / shapeSamples),
+			}
+			cell := image.Pt((x-b.Min.X)/ShapeCellWidth, (y-b.Min.Y)/ShapeCellHeight)
+			buf.Set(cell.X, cell.Y, screen.Cell{
+				Glyph: matchShape(glyphs, &shape),
+				FG:    c.foreground(p, colors.quantize(cell.X, cell.Y, mean)),
+			})
+		}
+		colors.nextRow()
+	}
+
+	return buf
+}
@@ -0,0 +1,111 @@
+package ascii
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+// shapeImage returns a cell-sized gray image with the pixels marked '#' in
+// rows set to level.
+func shapeImage(rows []string, level uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, ShapeCellWidth, ShapeCellHeight))
+	for y, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				img.SetGray(x, y, color.Gray{Y: level})
+			}
+		}
+	}
+	return img
+}
+
+func TestShapeGlyphs(t *testing.T) {
+	glyphs := shapeGlyphs()
+	if len(glyphs) != len(shapeChars) {
+		t.Fatalf("Expected %d glyphs, got %d", len(shapeChars), len(glyphs))
+	}
+
+	// Every part of the cell is scaled so the densest glyph there reaches 1
+	var peak shapeVector
+	for _, g := range glyphs {
+		for i, v := range g.shape {
+			peak[i] = max(peak[i], v)
+		}
+		if g.r == ' ' && g.shape != (shapeVector{}) {
+			t.Errorf("Expected space to have no ink, got %v", g.shape)
+		}
+	}
+	for i, p := range peak {
+		if p != 1 {
+			t.Errorf("Expected peak ink 1 in part %d, got %v", i, p)
+		}
+	}
+}
+
+func TestImageToShapes(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want string
+	}{
+		{"empty", []string{"...", "...", "...", "...", "...", "..."}, " "},
+		{"vertical", []string{".#.", ".#.", ".#.", ".#.", ".#.", ".#."}, "|"},
+		{"bottom", []string{"...", "...", "...", "...", "...", "###"}, "_"},
+		{"rising", []string{"..#", "..#", ".#.", ".#.", "#..", "#.."}, "/"},
+		{"falling", []string{"#..", "#..", ".#.", ".#.", "..#", "..#"}, "\\"},
+	}
+
+	converter := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := converter.ImageToShapes(termenv.Ascii, shapeImage(tt.rows, 128))
+			if got != tt.want+"\n" {
+				t.Errorf("Expected %q, got %q", tt.want+"\n", got)
+			}
+		})
+	}
+}
+
+func TestImageToShapes_Invert(t *testing.T) {
+	converter := NewConverter()
+	converter.SetInvert(true)
+
+	// A white cell has no ink once inverted
+	img := image.NewGray(image.Rect(0, 0, ShapeCellWidth, ShapeCellHeight))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	if got := converter.ImageToShapes(termenv.Ascii, img); got != " \n" {
+		t.Errorf("Expected a blank cell, got %q", got)
+	}
+}
+
+func TestImageToShapes_Size(t *testing.T) {
+	// Pixels that do not fill a whole cell are left out
+	img := image.NewRGBA(image.Rect(10, 20, 10+4*ShapeCellWidth+1, 20+2*ShapeCellHeight+2))
+	got := NewConverter().ImageToShapes(termenv.ANSI, img)
+
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if n := strings.Count(line, " "); n != 4 {
+			t.Errorf("Line %d: expected 4 cells, got %d", i, n)
+		}
+	}
+}
+
+func TestImageToShapes_WithGlobalColor(t *testing.T) {
+	converter := NewConverter()
+	converter.SetGlobalColor(color.RGBA{255, 0, 0, 255})
+
+	got := converter.ImageToShapes(termenv.ANSI, shapeImage([]string{"#"}, 255))
+	if !strings.Contains(got, "\x1b[91m") {
+		t.Errorf("Expected bright red foreground, got %q", got)
+	}
+}
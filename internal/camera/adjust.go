@@ -0,0 +1,319 @@
+package camera
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// AutoLevels selects how an Adjuster spreads the brightness of each frame
+// over the full range, so dim or washed out frames use all of the ramp.
+type AutoLevels string
+
+// Auto levels modes.
+const (
+	// AutoLevelsNone leaves the levels of frames as they are.
+	AutoLevelsNone AutoLevels = "none"
+	// AutoLevelsStretch stretches the darkest and brightest parts of each
+	// frame to black and white.
+	AutoLevelsStretch AutoLevels = "stretch"
+	// AutoLevelsEqualize equalizes the histogram of each frame, so every
+	// brightness is about equally common.
+	AutoLevelsEqualize AutoLevels = "equalize"
+)
+
+// Limits of the adjustments. Adjuster.Set keeps its settings within them.
+const (
+	MaxContrast   = 4.0
+	MinGamma      = 0.1
+	MaxGamma      = 10.0
+	MaxSaturation = 4.0
+)
+
+const (
+	// levelsClip is the fraction of pixels AutoLevelsStretch lets clip to
+	// black and to white, so a few specks do not prevent stretching.
+	levelsClip = 0.01
+	// levelsMinRange is the narrowest range of brightness AutoLevelsStretch
+	// stretches to the full range, so flat frames do not turn into noise.
+	levelsMinRange = 0.1
+	// levelsSmoothing is the fraction of the way to the levels of the
+	// current frame covered per frame, so exposure does not flicker.
+	levelsSmoothing = 0.1
+)
+
+// Adjustments are the brightness and color changes applied to frames.
+type Adjustments struct {
+	Brightness float64 // added to every channel, from -1 to 1
+	Contrast   float64 // factor for the distance from mid gray, 1 keeps it
+	Gamma      float64 // above 1 brightens midtones, below 1 darkens them
+	Saturation float64 // factor for the distance from gray, 0 is grayscale
+	Hue        float64 // rotation of hues in degrees
+	AutoLevels AutoLevels
+}
+
+// NoAdjustments leaves frames as they are.
+var NoAdjustments = Adjustments{Contrast: 1, Gamma: 1, Saturation: 1, AutoLevels: AutoLevelsNone}
+
+// IsIdentity reports whether the adjustments leave frames unchanged.
+func (a Adjustments) IsIdentity() bool {
+	return a.Brightness == 0 && a.Contrast == 1 && a.Gamma == 1 && a.Saturation == 1 &&
+		math.Mod(a.Hue, 360) == 0 && (a.AutoLevels == AutoLevelsNone || a.AutoLevels == "")
+}
+
+// changesColor reports whether the adjustments change hue or saturation.
+func (a Adjustments) changesColor() bool {
+	return a.Saturation != 1 || math.Mod(a.Hue, 360) != 0
+}
+
+// Adjuster applies Adjustments to a stream of frames. The automatic levels
+// follow the frames gradually instead of jumping with every frame.
+//
+// Adjuster is not safe for concurrent use.
+type Adjuster struct {
+	settings Adjustments
+
+	// Levels smoothed over the previous frames, for AutoLevels
+	primed bool
+	lo, hi float64
+	curve  [256]float64
+
+	out *image.RGBA
+}
+
+// NewAdjuster returns an adjuster applying a.
+func NewAdjuster(a Adjustments) *Adjuster {
+	adj := &Adjuster{}
+	adj.Set(a)
+	return adj
+}
+
+// Adjustments returns the adjustments currently applied.
+func (a *Adjuster) Adjustments() Adjustments {
+	return a.settings
+}
+
+// Set changes the adjustments applied to the following frames, keeping them
+// within their limits. Changing AutoLevels starts its smoothing over.
+func (a *Adjuster) Set(adj Adjustments) {
+	adj.Brightness = math.Max(-1, math.Min(adj.Brightness, 1))
+	adj.Contrast = math.Max(0, math.Min(adj.Contrast, MaxContrast))
+	adj.Gamma = math.Max(MinGamma, math.Min(adj.Gamma, MaxGamma))
+	adj.Saturation = math.Max(0, math.Min(adj.Saturation, MaxSaturation))
+	// Keep hue between -180 and 180 degrees
+	adj.Hue = math.Mod(adj.Hue, 360)
+	if adj.Hue > 180 {
+		adj.Hue -= 360
+	} else if adj.Hue <= -180 {
+		adj.Hue += 360
+	}
+	if adj.AutoLevels == "" {
+		adj.AutoLevels = AutoLevelsNone
+	}
+
+	if adj.AutoLevels != a.settings.AutoLevels {
+		a.primed = false
+	}
+	a.settings = adj
+}
+
+// Apply returns img with the adjustments applied, or img itself if they
+// change nothing. The returned image is reused by the next call.
+func (a *Adjuster) Apply(img image.Image) image.Image {
+	if a.settings.IsIdentity() || img.Bounds().Empty() {
+		return img
+	}
+
+	b := img.Bounds()
+	if a.out == nil || a.out.Rect != b {
+		a.out = image.NewRGBA(b)
+	}
+	draw.Draw(a.out, b, img, b.Min, draw.Src)
+
+	a.updateLevels(a.out)
+	tone := a.toneCurve()
+	recolor := a.settings.changesColor()
+	matrix := colorMatrix(a.settings.Saturation, a.settings.Hue)
+
+	pix := a.out.Pix
+	for i := 0; i+3 < len(pix); i += 4 {
+		r, g, bl, alpha := tone[pix[i]], tone[pix[i+1]], tone[pix[i+2]], pix[i+3]
+		if recolor {
+			r, g, bl = matrix.apply(r, g, bl)
+		}
+		// Channels are premultiplied, so they cannot exceed alpha
+		pix[i], pix[i+1], pix[i+2] = min(r, alpha), min(g, alpha), min(bl, alpha)
+	}
+	return a.out
+}
+
+// luma returns the brightness of a pixel from 0 to 255, weighting the
+// channels as ITU-R BT.601 does.
+func luma(r, g, b uint8) uint8 {
+	return uint8((299*int(r) + 587*int(g) + 114*int(b) + 500) / 1000)
+}
+
+// updateLevels moves the smoothed levels towards those of img.
+func (a *Adjuster) updateLevels(img *image.RGBA) {
+	if a.settings.AutoLevels == AutoLevelsNone {
+		return
+	}
+
+	var hist [256]int
+	n := 0
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		hist[luma(img.Pix[i], img.Pix[i+1], img.Pix[i+2])]++
+		n++
+	}
+
+	// Move the smoothed levels part of the way, or all of it at the start
+	f := levelsSmoothing
+	if !a.primed {
+		f = 1
+		a.primed = true
+	}
+
+	switch a.settings.AutoLevels {
+	case AutoLevelsStretch:
+		lo, hi := histogramLevels(&hist, n)
+		a.lo += (lo - a.lo) * f
+		a.hi += (hi - a.hi) * f
+	case AutoLevelsEqualize:
+		curve := equalizationCurve(&hist, n)
+		for i, v := range curve {
+			a.curve[i] += (v - a.curve[i]) * f
+		}
+	}
+}
+
+// histogramLevels returns the brightness from 0 to 1 below and above which
+// lie levelsClip of the n pixels counted in hist, at least levelsMinRange
+// apart.
+func histogramLevels(hist *[256]int, n int) (float64, float64) {
+	clip := int(levelsClip * float64(n))
+
+	lo, count := 0, 0
+	for ; lo < 255; lo++ {
+		if count += hist[lo]; count > clip {
+			break
+		}
+	}
+	hi, count := 255, 0
+	for ; hi > 0; hi-- {
+		if count += hist[hi]; count > clip {
+			break
+		}
+	}
+
+	l, h := float64(lo)/255, float64(hi)/255
+	if h-l < levelsMinRange {
+		// Widen the range around its middle, within 0 and 1
+		mid := math.Max(levelsMinRange/2, math.Min((l+h)/2, 1-levelsMinRange/2))
+		l, h = mid-levelsMinRange/2, mid+levelsMinRange/2
+	}
+	return l, h
+}
+
+// equalizationCurve returns the brightness from 0 to 1 each brightness maps
+// to when equalizing the histogram of n pixels, from their cumulative
+// distribution.
+func equalizationCurve(hist *[256]int, n int) [256]float64 {
+	var curve [256]float64
+
+	// The darkest brightness present becomes black
+	first := 0
+	for first < 255 && hist[first] == 0 {
+		first++
+	}
+	below := hist[first]
+	if n == below {
+		// A single brightness stays as it is
+		for i := range curve {
+			curve[i] = float64(i) / 255
+		}
+		return curve
+	}
+
+	count := 0
+	for i := range curve {
+		count += hist[i]
+		curve[i] = math.Max(0, float64(count-below)/float64(n-below))
+	}
+	return curve
+}
+
+// toneCurve returns what each channel value maps to with the automatic
+// levels, contrast, brightness and gamma applied, in that order.
+func (a *Adjuster) toneCurve() [256]uint8 {
+	s := a.settings
+
+	var tone [256]uint8
+	for i := range tone {
+		v := float64(i) / 255
+		switch s.AutoLevels {
+		case AutoLevelsStretch:
+			v = (v - a.lo) / (a.hi - a.lo)
+		case AutoLevelsEqualize:
+			v = a.curve[i]
+		}
+		v = (v-0.5)*s.Contrast + 0.5 + s.Brightness
+		v = math.Max(0, math.Min(v, 1))
+		v = math.Pow(v, 1/s.Gamma)
+		tone[i] = uint8(math.Round(v * 255))
+	}
+	return tone
+}
+
+// rgbMatrix transforms colors, in rows for red, green and blue.
+type rgbMatrix [3][3]float64
+
+// colorMatrix returns the matrix that scales saturation and rotates hue by
+// the given degrees in the YIQ color space, which keeps the brightness of
+// colors as they are.
+func colorMatrix(saturation, hue float64) rgbMatrix {
+	sin, cos := math.Sincos(hue * math.Pi / 180)
+	sin, cos = sin*saturation, cos*saturation
+
+	// RGB to YIQ and back
+	toYIQ := rgbMatrix{
+		{0.299, 0.587, 0.114},
+		{0.5959, -0.2746, -0.3213},
+		{0.2115, -0.5227, 0.3112},
+	}
+	fromYIQ := rgbMatrix{
+		{1, 0.9563, 0.6210},
+		{1, -0.2721, -0.6474},
+		{1, -1.1070, 1.7046},
+	}
+	// Keep Y, rotate and scale I and Q
+	rotate := rgbMatrix{
+		{1, 0, 0},
+		{0, cos, -sin},
+		{0, sin, cos},
+	}
+	return fromYIQ.mul(rotate.mul(toYIQ))
+}
+
+// mul returns m multiplied by n, which applies n first.
+func (m rgbMatrix) mul(n rgbMatrix) rgbMatrix {
+	var out rgbMatrix
+	for i := range out {
+		for j := range out[i] {
+			for k := range n {
+				out[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return out
+}
+
+// apply returns r, g, b transformed by m, clamped to 0 to 255.
+func (m rgbMatrix) apply(r, g, b uint8) (uint8, uint8, uint8) {
+	in := [3]float64{float64(r), float64(g), float64(b)}
+	var out [3]uint8
+	for i, row := range m {
+		v := row[0]*in[0] + row[1]*in[1] + row[2]*in[2]
+		out[i] = uint8(math.Max(0, math.Min(math.Round(v), 255)))
+	}
+	return out[0], out[1], out[2]
+}
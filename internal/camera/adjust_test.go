@@ -0,0 +1,196 @@
+package camera
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// gradientImage returns a 256x1 gray gradient from black to white, its
+// brightness squeezed between lo and hi.
+func gradientImage(lo, hi uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		v := lo + uint8(x*int(hi-lo)/255)
+		img.SetRGBA(x, 0, color.RGBA{v, v, v, 255})
+	}
+	return img
+}
+
+// brightnessRange returns the darkest and brightest red value in img.
+func brightnessRange(img image.Image) (uint8, uint8) {
+	lo, hi := uint8(255), uint8(0)
+	for _, row := range pixelRows(img) {
+		for _, v := range row {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	return lo, hi
+}
+
+func TestAdjuster_Identity(t *testing.T) {
+	img := gradientImage(0, 255)
+	if got := NewAdjuster(NoAdjustments).Apply(img); got != image.Image(img) {
+		t.Error("Expected the frame itself without adjustments")
+	}
+	// A full turn of hue changes nothing either
+	adj := NoAdjustments
+	adj.Hue = 360
+	if !adj.IsIdentity() {
+		t.Error("Expected a full turn of hue to be an identity")
+	}
+}
+
+func TestAdjuster_ToneControls(t *testing.T) {
+	gray := func(v uint8) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		img.SetRGBA(0, 0, color.RGBA{v, v, v, 255})
+		return img
+	}
+
+	tests := []struct {
+		name string
+		set  func(a *Adjustments)
+		in   uint8
+		want uint8
+	}{
+		{"brightness", func(a *Adjustments) { a.Brightness = 0.2 }, 100, 151},
+		{"darker", func(a *Adjustments) { a.Brightness = -0.5 }, 100, 0},
+		{"contrast", func(a *Adjustments) { a.Contrast = 2 }, 100, 73},
+		{"no contrast", func(a *Adjustments) { a.Contrast = 0 }, 10, 128},
+		{"gamma", func(a *Adjustments) { a.Gamma = 2 }, 64, 128},
+		{"gamma keeps black", func(a *Adjustments) { a.Gamma = 2 }, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adj := NoAdjustments
+			tt.set(&adj)
+			got := NewAdjuster(adj).Apply(gray(tt.in))
+			if r := pixelRows(got)[0][0]; r != tt.want {
+				t.Errorf("Expected %d to become %d, got %d", tt.in, tt.want, r)
+			}
+		})
+	}
+}
+
+func TestAdjuster_Color(t *testing.T) {
+	red := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	red.SetRGBA(0, 0, color.RGBA{200, 50, 50, 255})
+
+	adj := NoAdjustments
+	adj.Saturation = 0
+	got := NewAdjuster(adj).Apply(red).At(0, 0).(color.RGBA)
+	if got.R != got.G || got.G != got.B {
+		t.Errorf("Expected gray without saturation, got %v", got)
+	}
+	if want := luma(200, 50, 50); absDiff(got.R, want) > 1 {
+		t.Errorf("Expected the brightness %d to stay, got %v", want, got)
+	}
+
+	// Turning the hue by a third moves red towards green or blue
+	adj = NoAdjustments
+	adj.Hue = 120
+	got = NewAdjuster(adj).Apply(red).At(0, 0).(color.RGBA)
+	if got.R >= got.G && got.R >= got.B {
+		t.Errorf("Expected red to turn, got %v", got)
+	}
+
+	// Gray has no hue to turn
+	gray := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	gray.SetRGBA(0, 0, color.RGBA{90, 90, 90, 255})
+	if got := NewAdjuster(adj).Apply(gray).At(0, 0).(color.RGBA); got != (color.RGBA{90, 90, 90, 255}) {
+		t.Errorf("Expected gray to stay gray, got %v", got)
+	}
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestAdjuster_Set(t *testing.T) {
+	a := NewAdjuster(Adjustments{Brightness: 3, Contrast: -1, Gamma: 0, Saturation: 9, Hue: 270})
+	got := a.Adjustments()
+	want := Adjustments{Brightness: 1, Contrast: 0, Gamma: MinGamma, Saturation: MaxSaturation, Hue: -90, AutoLevels: AutoLevelsNone}
+	if got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestAdjuster_AutoLevels(t *testing.T) {
+	for _, mode := range []AutoLevels{AutoLevelsStretch, AutoLevelsEqualize} {
+		t.Run(string(mode), func(t *testing.T) {
+			adj := NoAdjustments
+			adj.AutoLevels = mode
+			a := NewAdjuster(adj)
+
+			// A dim, low contrast frame is spread over the full range
+			lo, hi := brightnessRange(a.Apply(gradientImage(40, 100)))
+			if lo > 5 || hi < 250 {
+				t.Errorf("Expected the full range, got %d to %d", lo, hi)
+			}
+		})
+	}
+}
+
+func TestAdjuster_AutoLevelsFlat(t *testing.T) {
+	adj := NoAdjustments
+	adj.AutoLevels = AutoLevelsStretch
+
+	// A flat frame keeps about its brightness instead of turning into noise
+	lo, hi := brightnessRange(NewAdjuster(adj).Apply(gradientImage(120, 130)))
+	if lo < 50 || hi > 205 {
+		t.Errorf("Expected a moderate stretch of a flat frame, got %d to %d", lo, hi)
+	}
+}
+
+func TestAdjuster_AutoLevelsSmoothing(t *testing.T) {
+	adj := NoAdjustments
+	adj.AutoLevels = AutoLevelsStretch
+	a := NewAdjuster(adj)
+
+	a.Apply(gradientImage(0, 100))
+
+	// The levels follow a brighter frame over several frames, so mid gray
+	// starts out too bright and settles
+	bright := gradientImage(0, 255)
+	var mid []uint8
+	for i := 0; i < 100; i++ {
+		mid = append(mid, pixelRows(a.Apply(bright))[0][128])
+	}
+	if mid[0] < 200 || mid[0] <= mid[10] || mid[10] <= mid[99] {
+		t.Errorf("Expected mid gray to settle gradually, got %d, %d and %d", mid[0], mid[10], mid[99])
+	}
+	if absDiff(mid[99], 128) > 3 {
+		t.Errorf("Expected the levels to settle on the new frame, got %d", mid[99])
+	}
+}
+
+func TestHistogramLevels(t *testing.T) {
+	var hist [256]int
+	hist[0] = 1 // a speck below the clip
+	hist[50] = 99
+	hist[150] = 99
+	hist[255] = 1
+
+	lo, hi := histogramLevels(&hist, 200)
+	if math.Abs(lo-50.0/255) > 1e-9 || math.Abs(hi-150.0/255) > 1e-9 {
+		t.Errorf("Expected 50 to 150, got %g to %g", lo*255, hi*255)
+	}
+}
+
+func TestEqualizationCurve(t *testing.T) {
+	var hist [256]int
+	hist[10] = 50
+	hist[20] = 25
+	hist[30] = 25
+
+	curve := equalizationCurve(&hist, 100)
+	if curve[10] != 0 || curve[20] != 0.5 || curve[30] != 1 || curve[255] != 1 {
+		t.Errorf("Unexpected curve: %g, %g, %g", curve[10], curve[20], curve[30])
+	}
+}
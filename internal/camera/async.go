@@ -0,0 +1,197 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// asyncRetryDelay is how long the capture worker waits after a failed read
+// before trying again.
+const asyncRetryDelay = 100 * time.Millisecond
+
+// FrameStats counts frames passing through an AsyncSource.
+type FrameStats struct {
+	Captured uint64 // frames read from the underlying source
+	Dropped  uint64 // frames replaced by a newer one before they were read
+	Rendered uint64 // frames handed to the reader
+}
+
+// AsyncSource reads frames from a live source on a background goroutine, so
+// slow rendering does not throttle capture or leave stale frames queued in
+// the driver. Captured frames go into a small ring buffer and readers always
+// get the newest one; older unread frames are counted as dropped.
+//
+// AsyncSource hides the optional interfaces of the wrapped source, as those
+// are not safe to use while the worker is reading.
+type AsyncSource struct {
+	src    FrameSource
+	cancel context.CancelFunc
+	done   chan struct{}
+	ready  chan struct{}
+
+	mu     sync.Mutex
+	ring   []image.Image
+	head   int // index of the newest frame
+	unread int
+	err    error // last read error, reported when no newer frame arrived
+	width  uint
+	height uint
+	stats  FrameStats
+}
+
+var _ FrameSource = (*AsyncSource)(nil)
+
+// NewAsyncSource starts capturing from src into a ring buffer holding up to
+// size frames. Closing the AsyncSource closes src.
+func NewAsyncSource(src FrameSource, size int) *AsyncSource {
+	ctx, cancel := context.WithCancel(context.Background())
+	w, h := src.Dimensions()
+
+	a := &AsyncSource{
+		src:    src,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		ready:  make(chan struct{}, 1),
+		ring:   make([]image.Image, max(size, 1)),
+		head:   -1,
+		width:  w,
+		height: h,
+	}
+	go a.capture(ctx)
+
+	return a
+}
+
+// capture reads frames until ctx is cancelled or the source ends.
+func (a *AsyncSource) capture(ctx context.Context) {
+	defer close(a.done)
+
+	for ctx.Err() == nil {
+		img, err := a.src.ReadFrameWithContext(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			a.mu.Lock()
+			a.err = err
+			a.mu.Unlock()
+			a.notify()
+
+			if errors.IsEndOfStream(err) || errors.IsFatal(err) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(asyncRetryDelay):
+			}
+			continue
+		}
+
+		a.push(img)
+		a.notify()
+	}
+}
+
+// push stores img as the newest frame, overwriting the oldest one when the
+// ring is full.
+func (a *AsyncSource) push(img image.Image) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.head = (a.head + 1) % len(a.ring)
+	a.ring[a.head] = img
+	if a.unread == len(a.ring) {
+		a.stats.Dropped++
+	} else {
+		a.unread++
+	}
+	a.err = nil
+	a.stats.Captured++
+
+	b := img.Bounds()
+	a.width, a.height = uint(b.Dx()), uint(b.Dy())
+}
+
+// notify wakes up a waiting reader.
+func (a *AsyncSource) notify() {
+	select {
+	case a.ready <- struct{}{}:
+	default:
+	}
+}
+
+// ReadFrameWithContext returns the newest captured frame, waiting for one if
+// every captured frame has already been read. Read errors of the underlying
+// source are returned when no newer frame arrived since.
+func (a *AsyncSource) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	for {
+		if img, ok, err := a.take(); ok {
+			return img, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+		case <-a.done:
+			// The worker may have stored a frame or error just before exiting
+			if img, ok, err := a.take(); ok {
+				return img, err
+			}
+			return nil, fmt.Errorf("context cancelled: %w", context.Canceled)
+		case <-a.ready:
+		}
+	}
+}
+
+// take returns the newest unread frame or the pending error, if any.
+func (a *AsyncSource) take() (image.Image, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.unread > 0 {
+		a.stats.Dropped += uint64(a.unread - 1)
+		a.stats.Rendered++
+		a.unread = 0
+		return a.ring[a.head], true, nil
+	}
+
+	if err := a.err; err != nil {
+		// The worker stops on these, so they stay pending; others are reported once
+		if !errors.IsEndOfStream(err) && !errors.IsFatal(err) {
+			a.err = nil
+		}
+		return nil, true, err
+	}
+
+	return nil, false, nil
+}
+
+// Stats returns the frame counters.
+func (a *AsyncSource) Stats() FrameStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stats
+}
+
+// Dimensions returns the size of the newest captured frame, or the size
+// reported by the source before the first frame arrives.
+func (a *AsyncSource) Dimensions() (uint, uint) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.width, a.height
+}
+
+// Close stops the capture worker and closes the underlying source.
+func (a *AsyncSource) Close() {
+	a.cancel()
+	<-a.done
+	a.src.Close()
+}
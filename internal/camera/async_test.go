@@ -0,0 +1,149 @@
+package camera
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// chanSource is a FrameSource fed by a test through a channel.
+type chanSource struct {
+	frames chan image.Image
+	errs   chan error
+	closed bool
+}
+
+func newChanSource() *chanSource {
+	return &chanSource{
+		frames: make(chan image.Image),
+		errs:   make(chan error),
+	}
+}
+
+func (c *chanSource) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case img := <-c.frames:
+		return img, nil
+	case err := <-c.errs:
+		return nil, err
+	}
+}
+
+func (c *chanSource) Close() { c.closed = true }
+
+func (c *chanSource) Dimensions() (uint, uint) { return 8, 8 }
+
+// numberedFrame returns a frame of width n, so frames can be told apart.
+func numberedFrame(n int) image.Image {
+	return image.NewRGBA(image.Rect(0, 0, n, 1))
+}
+
+// waitCaptured waits until the worker has captured n frames.
+func waitCaptured(t *testing.T, a *AsyncSource, n uint64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for a.Stats().Captured < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d captured frames, got %d", n, a.Stats().Captured)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncSource_LatestFrame(t *testing.T) {
+	src := newChanSource()
+	a := NewAsyncSource(src, 2)
+	defer a.Close()
+
+	for i := 1; i <= 3; i++ {
+		src.frames <- numberedFrame(i)
+	}
+	waitCaptured(t, a, 3)
+
+	img, err := a.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+	if img.Bounds().Dx() != 3 {
+		t.Errorf("Expected the newest frame, got frame %d", img.Bounds().Dx())
+	}
+
+	stats := a.Stats()
+	if stats.Captured != 3 || stats.Dropped != 2 || stats.Rendered != 1 {
+		t.Errorf("Expected 3 captured, 2 dropped, 1 rendered, got %+v", stats)
+	}
+
+	if w, h := a.Dimensions(); w != 3 || h != 1 {
+		t.Errorf("Expected dimensions of the newest frame, got %dx%d", w, h)
+	}
+}
+
+func TestAsyncSource_WaitsForNewFrame(t *testing.T) {
+	src := newChanSource()
+	a := NewAsyncSource(src, 2)
+	defer a.Close()
+
+	src.frames <- numberedFrame(1)
+	if _, err := a.ReadFrameWithContext(context.Background()); err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	// No new frame yet, so the read must block until the context expires
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.ReadFrameWithContext(ctx); err == nil {
+		t.Error("Expected error when no new frame arrives, got none")
+	}
+
+	go func() { src.frames <- numberedFrame(2) }()
+	img, err := a.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+	if img.Bounds().Dx() != 2 {
+		t.Errorf("Expected frame 2, got frame %d", img.Bounds().Dx())
+	}
+}
+
+func TestAsyncSource_Errors(t *testing.T) {
+	src := newChanSource()
+	a := NewAsyncSource(src, 2)
+	defer a.Close()
+
+	// Retryable errors are reported once
+	src.errs <- errors.NewCameraError(0, "read", errors.ErrCameraReadFailed)
+	if _, err := a.ReadFrameWithContext(context.Background()); !errors.IsRetryable(err) {
+		t.Errorf("Expected retryable error, got %v", err)
+	}
+
+	// End of stream is reported on every read
+	src.errs <- errors.NewFileError("test", "read", errors.ErrEndOfStream)
+	for i := 0; i < 2; i++ {
+		if _, err := a.ReadFrameWithContext(context.Background()); !errors.IsEndOfStream(err) {
+			t.Errorf("Expected end of stream, got %v", err)
+		}
+	}
+}
+
+func TestAsyncSource_Close(t *testing.T) {
+	src := newChanSource()
+	a := NewAsyncSource(src, 2)
+
+	if w, h := a.Dimensions(); w != 8 || h != 8 {
+		t.Errorf("Expected source dimensions before the first frame, got %dx%d", w, h)
+	}
+
+	a.Close()
+	if !src.closed {
+		t.Error("Expected Close to close the underlying source")
+	}
+
+	if _, err := a.ReadFrameWithContext(context.Background()); err == nil {
+		t.Error("Expected error reading from a closed source, got none")
+	}
+}
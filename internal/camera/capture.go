@@ -1,25 +1,55 @@
-// Package camera provides functionality for webcam capture and image processing.
+//go:build cgo && !noopencv
+
 package camera
 
 import (
 	"context"
 	"fmt"
 	"image"
-	"image/color"
+	"sync/atomic"
 
 	"github.com/muesli/asciicam/internal/errors"
-	"github.com/nfnt/resize"
 	"gocv.io/x/gocv"
 )
 
 // Capture handles webcam capture operations.
+// It is the OpenCV-backed implementation of FrameSource.
 type Capture struct {
 	webcam   *gocv.VideoCapture
+	frame    gocv.Mat // reused for every read
+	scaled   gocv.Mat // reused for downscaled frames
+	pool     *FramePool
 	deviceID int
 	width    uint
 	height   uint
+	maxSize  atomic.Uint64 // width<<32 | height, see SetMaxSize
+}
+
+var (
+	_ FrameSource    = (*Capture)(nil)
+	_ PropertySetter = (*Capture)(nil)
+	_ Downscaler     = (*Capture)(nil)
+	_ FramePooler    = (*Capture)(nil)
+)
+
+// captureProperties maps camera properties to OpenCV capture properties.
+var captureProperties = map[Property]gocv.VideoCaptureProperties{
+	PropertyAutoExposure:     gocv.VideoCaptureAutoExposure,
+	PropertyExposure:         gocv.VideoCaptureExposure,
+	PropertyGain:             gocv.VideoCaptureGain,
+	PropertyAutoFocus:        gocv.VideoCaptureAutoFocus,
+	PropertyFocus:            gocv.VideoCaptureFocus,
+	PropertyBrightness:       gocv.VideoCaptureBrightness,
+	PropertyContrast:         gocv.VideoCaptureContrast,
+	PropertySaturation:       gocv.VideoCaptureSaturation,
+	PropertyAutoWhiteBalance: gocv.VideoCaptureAutoWB,
+	PropertyWhiteBalance:     gocv.VideoCaptureWBTemperature,
+	PropertyFPS:              gocv.VideoCaptureFPS,
 }
 
+// OpenCVAvailable reports whether this binary was built with OpenCV support.
+const OpenCVAvailable = true
+
 // NewCapture creates a new camera capture instance.
 func NewCapture(deviceID int, width, height uint) (*Capture, error) {
 	webcam, err := gocv.OpenVideoCapture(deviceID)
@@ -37,18 +67,66 @@ func NewCapture(deviceID int, width, height uint) (*Capture, error) {
 		return nil, errors.NewCameraError(deviceID, "open", errors.ErrCameraNotFound)
 	}
 
+	// The driver picks the closest size it supports, which may differ
+	if w, h := webcam.Get(gocv.VideoCaptureFrameWidth), webcam.Get(gocv.VideoCaptureFrameHeight); w > 0 && h > 0 {
+		width, height = uint(w), uint(h)
+	}
+
 	return &Capture{
 		webcam:   webcam,
+		frame:    gocv.NewMat(),
+		scaled:   gocv.NewMat(),
 		deviceID: deviceID,
 		width:    width,
 		height:   height,
 	}, nil
 }
 
+// SetMaxSize makes the capture shrink frames inside OpenCV, before they are
+// converted, to the smallest size covering width x height. It may be called
+// while frames are read on another goroutine, for example when the terminal
+// is resized.
+func (c *Capture) SetMaxSize(width, height uint) {
+	c.maxSize.Store(uint64(uint32(width))<<32 | uint64(uint32(height)))
+}
+
+// SetFramePool makes the capture take its frames from pool. Readers hand
+// frames back with pool.Put once they are done with them. This must be
+// called before reading frames from another goroutine.
+func (c *Capture) SetFramePool(pool *FramePool) {
+	c.pool = pool
+}
+
+// SetProperties applies camera properties through VideoCapture.Set and reads
+// back the values the driver accepted. OpenCV does not report rejected
+// properties, so they show up as results whose Actual differs from Requested.
+func (c *Capture) SetProperties(props Properties) []PropertyResult {
+	props, order := props.resolve()
+
+	results := make([]PropertyResult, 0, len(order))
+	for _, prop := range order {
+		cvProp := captureProperties[prop]
+		value := props[prop]
+
+		if prop == PropertyAutoExposure {
+			c.webcam.Set(cvProp, exposureMode(value))
+			results = append(results, PropertyResult{Property: prop, Requested: value, Actual: autoExposure(c.webcam.Get(cvProp))})
+			continue
+		}
+
+		c.webcam.Set(cvProp, value)
+		results = append(results, PropertyResult{Property: prop, Requested: value, Actual: c.webcam.Get(cvProp)})
+	}
+
+	return results
+}
+
 // Close closes the camera capture.
 func (c *Capture) Close() {
 	if c.webcam != nil {
 		c.webcam.Close()
+		c.frame.Close()
+		c.scaled.Close()
 	}
 }
 
@@ -64,16 +142,13 @@ func (c *Capture) ReadFrameWithContext(ctx context.Context) (image.Image, error)
 		return nil, fmt.Errorf("context cancelled: %w", err)
 	}
 
-	frame := gocv.NewMat()
-	defer frame.Close()
-
 	// Read a new frame from the webcam
-	if ok := c.webcam.Read(&frame); !ok {
+	if ok := c.webcam.Read(&c.frame); !ok {
 		return nil, errors.NewCameraError(c.deviceID, "read", errors.ErrCameraReadFailed)
 	}
 
 	// Skip empty frames
-	if frame.Empty() {
+	if c.frame.Empty() {
 		return nil, errors.NewCameraError(c.deviceID, "read", fmt.Errorf("%w: empty frame", errors.ErrCameraReadFailed))
 	}
 
@@ -82,55 +157,88 @@ func (c *Capture) ReadFrameWithContext(ctx context.Context) (image.Image, error)
 		return nil, fmt.Errorf("context cancelled during frame processing: %w", err)
 	}
 
+	// We only render at terminal resolution, shrinking in OpenCV is far
+	// cheaper than converting the full frame
+	frame := c.frame
+	maxSize := c.maxSize.Load()
+	if w, h, ok := coverSize(frame.Cols(), frame.Rows(), int(maxSize>>32), int(uint32(maxSize))); ok {
+		gocv.Resize(frame, &c.scaled, image.Pt(w, h), 0, 0, gocv.InterpolationArea)
+		frame = c.scaled
+	}
+
 	// Convert gocv Mat to Go image
-	img := c.matToImage(frame)
-	if img == nil {
-		return nil, errors.NewImageError("convert", fmt.Sprintf("%dx%d", c.width, c.height), errors.ErrImageProcessFailed)
+	return c.matToImage(frame)
+}
+
+// matToImage converts a frame read from the webcam to an image.RGBA taken
+// from the capture's frame pool. The frame's own size is used, as drivers
+// may deliver a different size than requested or change it mid-stream; the
+// capture's dimensions follow it.
+func (c *Capture) matToImage(mat gocv.Mat) (*image.RGBA, error) {
+	if err := checkMat(mat); err != nil {
+		return nil, err
 	}
 
+	img := c.pool.Get(image.Rect(0, 0, mat.Cols(), mat.Rows()))
+	copyMat(img, mat)
+
+	c.width, c.height = uint(mat.Cols()), uint(mat.Rows())
 	return img, nil
 }
 
-// matToImage converts a gocv Mat to Go's native image.RGBA format.
-// This is necessary because gocv uses OpenCV's BGR color format, while
-// Go's standard image library uses RGBA format.
-func (c *Capture) matToImage(mat gocv.Mat) *image.RGBA {
-	// Safe conversion with bounds checking
-	const maxInt = int(^uint(0) >> 1)
-	width := int(c.width)
-	height := int(c.height)
-	if c.width > uint(maxInt) {
-		width = maxInt
-	}
-	if c.height > uint(maxInt) {
-		height = maxInt
+// matToRGBA converts a Mat holding 8-bit BGR pixels to a new image.RGBA of
+// the Mat's own size.
+func matToRGBA(mat gocv.Mat) (*image.RGBA, error) {
+	if err := checkMat(mat); err != nil {
+		return nil, err
 	}
 
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img := image.NewRGBA(image.Rect(0, 0, mat.Cols(), mat.Rows()))
+	copyMat(img, mat)
+	return img, nil
+}
+
+// checkMat returns an ImageError unless mat holds 8-bit BGR pixels. Other Mat
+// types wrap errors.ErrUnsupportedFormat.
+func checkMat(mat gocv.Mat) error {
+	width, height := mat.Cols(), mat.Rows()
+	if width <= 0 || height <= 0 {
+		return errors.NewImageError("convert", fmt.Sprintf("%dx%d", width, height), errors.ErrInvalidDimensions)
+	}
+	if mat.Type() != gocv.MatTypeCV8UC3 {
+		return errors.NewImageError("convert", fmt.Sprintf("%dx%d", width, height), fmt.Errorf("%w: Mat type %d with %d channels, want 8-bit BGR", errors.ErrUnsupportedFormat, mat.Type(), mat.Channels()))
+	}
+	return nil
+}
 
-	// Create a copy of the Mat to avoid modifying the original
-	bgrMat := mat.Clone()
-	defer bgrMat.Close() // Ensure the Mat is properly closed to avoid memory leaks
+// copyMat converts the BGR pixels of mat into img, which must have the same
+// size. OpenCV stores colors as BGR, while Go's standard image library uses
+// RGBA. The pixels are read straight from OpenCV's memory without copying
+// them into Go first.
+func copyMat(img *image.RGBA, mat gocv.Mat) {
+	data := mat.DataPtrUint8()
+	width, height, stride := mat.Cols(), mat.Rows(), mat.Step()
+	if len(data) >= (height-1)*stride+width*3 {
+		bgrToRGBA(img, data, stride)
+		return
+	}
 
-	// Copy the data from mat to img, converting BGR to RGBA
+	// Not continuous in memory, fall back to reading pixel by pixel
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			pixel := bgrMat.GetVecbAt(y, x)
-			img.SetRGBA(x, y, color.RGBA{
-				B: pixel[0], // OpenCV stores colors as BGR
-				G: pixel[1],
-				R: pixel[2],
-				A: 255, // Set full opacity
-			})
+			pixel := mat.GetVecbAt(y, x)
+			i := img.PixOffset(x, y)
+			img.Pix[i+0] = pixel[2]
+			img.Pix[i+1] = pixel[1]
+			img.Pix[i+2] = pixel[0]
+			img.Pix[i+3] = 255
 		}
 	}
-
-	return img
 }
 
 // ResizeImage resizes an image to the specified dimensions.
 func (c *Capture) ResizeImage(img image.Image, width, height uint) image.Image {
-	return resize.Resize(width, height, img, resize.Bilinear)
+	return ResizeImage(img, width, height)
 }
 
 // GetDeviceID returns the device ID of the camera.
@@ -138,7 +246,15 @@ func (c *Capture) GetDeviceID() int {
 	return c.deviceID
 }
 
-// GetDimensions returns the width and height of the camera.
+// GetDimensions returns the size of the frames the capture delivers, which is
+// the size negotiated with the driver until the first frame arrives and the
+// size of the most recent frame after that.
 func (c *Capture) GetDimensions() (uint, uint) {
 	return c.width, c.height
 }
+
+// Dimensions returns the width and height of the camera.
+// It satisfies the FrameSource interface.
+func (c *Capture) Dimensions() (uint, uint) {
+	return c.GetDimensions()
+}
@@ -1,10 +1,15 @@
+//go:build cgo && !noopencv
+
 package camera
 
 import (
+	"bytes"
+	stderrors "errors"
 	"image"
 	"image/color"
 	"testing"
 
+	"github.com/muesli/asciicam/internal/errors"
 	"gocv.io/x/gocv"
 )
 
@@ -43,6 +48,15 @@ func TestGetDimensions(t *testing.T) {
 	}
 }
 
+func TestCapture_Dimensions(t *testing.T) {
+	capture := &Capture{width: 1280, height: 720}
+
+	w, h := capture.Dimensions()
+	if w != 1280 || h != 720 {
+		t.Errorf("Expected dimensions 1280x720, got %dx%d", w, h)
+	}
+}
+
 func TestMatToImage(t *testing.T) {
 	// Test the conversion logic without requiring actual camera hardware
 	capture := &Capture{
@@ -60,10 +74,9 @@ func TestMatToImage(t *testing.T) {
 	defer mat.Close()
 
 	// Test the conversion
-	img := capture.matToImage(mat)
-
-	if img == nil {
-		t.Fatal("matToImage returned nil")
+	img, err := capture.matToImage(mat)
+	if err != nil {
+		t.Fatalf("matToImage returned error: %v", err)
 	}
 
 	bounds := img.Bounds()
@@ -83,10 +96,9 @@ func TestMatToImage_LargeDimensions(t *testing.T) {
 	mat := gocv.NewMatWithSize(1000, 1000, gocv.MatTypeCV8UC3)
 	defer mat.Close()
 
-	img := capture.matToImage(mat)
-
-	if img == nil {
-		t.Fatal("matToImage returned nil for large dimensions")
+	img, err := capture.matToImage(mat)
+	if err != nil {
+		t.Fatalf("matToImage returned error for large dimensions: %v", err)
 	}
 
 	bounds := img.Bounds()
@@ -95,6 +107,48 @@ func TestMatToImage_LargeDimensions(t *testing.T) {
 	}
 }
 
+func TestMatToImage_NegotiatedSize(t *testing.T) {
+	// The driver delivers 1280x720 instead of the requested 1920x1080
+	capture := &Capture{
+		deviceID: 0,
+		width:    1920,
+		height:   1080,
+	}
+
+	mat := gocv.NewMatWithSize(720, 1280, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	img, err := capture.matToImage(mat)
+	if err != nil {
+		t.Fatalf("matToImage returned error: %v", err)
+	}
+
+	if b := img.Bounds(); b.Dx() != 1280 || b.Dy() != 720 {
+		t.Errorf("Expected 1280x720 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	if w, h := capture.GetDimensions(); w != 1280 || h != 720 {
+		t.Errorf("Expected dimensions to follow the frame, got %dx%d", w, h)
+	}
+}
+
+func TestMatToImage_UnsupportedType(t *testing.T) {
+	capture := &Capture{width: 4, height: 4}
+
+	mat := gocv.NewMatWithSize(4, 4, gocv.MatTypeCV8UC1)
+	defer mat.Close()
+
+	_, err := capture.matToImage(mat)
+	if err == nil {
+		t.Fatal("Expected error for single channel Mat, got none")
+	}
+
+	var imgErr *errors.ImageError
+	if !stderrors.As(err, &imgErr) || !stderrors.Is(err, errors.ErrUnsupportedFormat) {
+		t.Errorf("Expected ImageError wrapping ErrUnsupportedFormat, got %v", err)
+	}
+}
+
 func TestResizeImage(t *testing.T) {
 	capture := &Capture{}
 
@@ -168,10 +222,9 @@ func TestMatToImage_OverflowProtection(t *testing.T) {
 	defer mat.Close()
 
 	// This should not panic
-	img := capture.matToImage(mat)
-
-	if img == nil {
-		t.Fatal("matToImage returned nil")
+	img, err := capture.matToImage(mat)
+	if err != nil {
+		t.Fatalf("matToImage returned error: %v", err)
 	}
 
 	// The image dimensions should match the capture dimensions
@@ -194,11 +247,107 @@ func BenchmarkMatToImage(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		img := capture.matToImage(mat)
+		img, _ := capture.matToImage(mat)
 		_ = img // Prevent optimization
 	}
 }
 
+// matToImageSlow is the per-pixel conversion matToImage used before, kept as
+// a reference for the tests and benchmarks below.
+func matToImageSlow(mat gocv.Mat) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, mat.Cols(), mat.Rows()))
+	for y := 0; y < mat.Rows(); y++ {
+		for x := 0; x < mat.Cols(); x++ {
+			pixel := mat.GetVecbAt(y, x)
+			img.Set(x, y, color.RGBA{pixel[2], pixel[1], pixel[0], 255})
+		}
+	}
+	return img
+}
+
+// testMat returns a width x height BGR Mat filled with a gradient.
+func testMat(width, height int) gocv.Mat {
+	mat := gocv.NewMatWithSize(height, width, gocv.MatTypeCV8UC3)
+	data := mat.DataPtrUint8()
+	for i := range data {
+		data[i] = uint8(i * 7)
+	}
+	return mat
+}
+
+func TestMatToImage_MatchesSlowPath(t *testing.T) {
+	mat := testMat(33, 17)
+	defer mat.Close()
+
+	capture := &Capture{pool: NewFramePool(1)}
+	img, err := capture.matToImage(mat)
+	if err != nil {
+		t.Fatalf("matToImage returned error: %v", err)
+	}
+
+	if want := matToImageSlow(mat); !bytes.Equal(img.Pix, want.Pix) {
+		t.Error("Expected fast conversion to match per-pixel conversion")
+	}
+}
+
+func TestMatToImage_Region(t *testing.T) {
+	// A region of a larger Mat is not continuous in memory
+	mat := testMat(64, 32)
+	defer mat.Close()
+	region := mat.Region(image.Rect(5, 3, 37, 19))
+	defer region.Close()
+
+	img, err := matToRGBA(region)
+	if err != nil {
+		t.Fatalf("matToRGBA returned error: %v", err)
+	}
+
+	if want := matToImageSlow(region); !bytes.Equal(img.Pix, want.Pix) {
+		t.Error("Expected region conversion to match per-pixel conversion")
+	}
+}
+
+func BenchmarkMatToImage_Slow1080p(b *testing.B) {
+	mat := testMat(1920, 1080)
+	defer mat.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = matToImageSlow(mat)
+	}
+}
+
+func BenchmarkMatToImage_1080p(b *testing.B) {
+	mat := testMat(1920, 1080)
+	defer mat.Close()
+	pool := NewFramePool(1)
+	capture := &Capture{pool: pool}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		img, _ := capture.matToImage(mat)
+		pool.Put(img)
+	}
+}
+
+func BenchmarkMatToImage_1080pDownscaled(b *testing.B) {
+	// What ReadFrameWithContext does for a 160x45 cell terminal
+	mat := testMat(1920, 1080)
+	defer mat.Close()
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	pool := NewFramePool(1)
+	capture := &Capture{pool: pool}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w, h, _ := coverSize(mat.Cols(), mat.Rows(), 160, 90)
+		gocv.Resize(mat, &scaled, image.Pt(w, h), 0, 0, gocv.InterpolationArea)
+		img, _ := capture.matToImage(scaled)
+		pool.Put(img)
+	}
+}
+
 func BenchmarkResizeImage(b *testing.B) {
 	capture := &Capture{}
 
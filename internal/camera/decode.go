@@ -0,0 +1,131 @@
+package camera
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"sync"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// decodeYUYV converts a packed YUYV 4:2:2 frame (Y0 U Y1 V per pixel pair)
+// with limited range samples and rows stride bytes apart to an image.RGBA.
+func decodeYUYV(buf []byte, width, height, stride int) (*image.RGBA, error) {
+	if width%2 != 0 || stride < width*2 {
+		return nil, errors.NewImageError("yuyv", fmt.Sprintf("%dx%d", width, height), fmt.Errorf("%w: odd width or stride of %d bytes", errors.ErrImageDecodeFailed, stride))
+	}
+	if height > 0 && len(buf) < stride*(height-1)+width*2 {
+		return nil, errors.NewImageError("yuyv", fmt.Sprintf("%dx%d", width, height), fmt.Errorf("%w: short frame of %d bytes", errors.ErrImageDecodeFailed, len(buf)))
+	}
+
+	ycc := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio422)
+	for y := 0; y < height; y++ {
+		row := buf[y*stride : y*stride+width*2]
+		for x := 0; x < width/2; x++ {
+			ycc.Y[y*ycc.YStride+2*x] = row[4*x]
+			ycc.Cb[y*ycc.CStride+x] = row[4*x+1]
+			ycc.Y[y*ycc.YStride+2*x+1] = row[4*x+2]
+			ycc.Cr[y*ycc.CStride+x] = row[4*x+3]
+		}
+	}
+	expandVideoRange(ycc)
+
+	img := image.NewRGBA(ycc.Rect)
+	draw.Draw(img, img.Rect, ycc, image.Point{}, draw.Src)
+	return img, nil
+}
+
+// bgrToRGBA converts packed 8-bit BGR rows, stride bytes apart, into dst.
+func bgrToRGBA(dst *image.RGBA, src []byte, stride int) {
+	w, h := dst.Rect.Dx(), dst.Rect.Dy()
+	for y := 0; y < h; y++ {
+		in := src[y*stride : y*stride+w*3]
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+w*4]
+		for i, j := 0, 0; i < len(in); i, j = i+3, j+4 {
+			out[j+0] = in[i+2]
+			out[j+1] = in[i+1]
+			out[j+2] = in[i+0]
+			out[j+3] = 255
+		}
+	}
+}
+
+// decodeMJPEG decodes a single Motion-JPEG frame to an image.RGBA. Many
+// webcams omit the Huffman tables from their frames and rely on the
+// standard tables instead, so those are inserted when missing.
+func decodeMJPEG(buf []byte) (*image.RGBA, error) {
+	src, err := jpeg.Decode(bytes.NewReader(withHuffmanTables(buf)))
+	if err != nil {
+		return nil, errors.NewImageError("mjpeg", "", fmt.Errorf("%w: %v", errors.ErrImageDecodeFailed, err))
+	}
+
+	if img, ok := src.(*image.RGBA); ok {
+		return img, nil
+	}
+
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Rect, src, src.Bounds().Min, draw.Src)
+	return img, nil
+}
+
+// withHuffmanTables returns frame with the standard JPEG Huffman tables
+// inserted before the start of scan, unless it already defines its own.
+func withHuffmanTables(frame []byte) []byte {
+	if len(frame) < 4 || frame[0] != 0xff || frame[1] != 0xd8 {
+		return frame
+	}
+
+	for i := 2; i+4 <= len(frame); {
+		if frame[i] != 0xff {
+			return frame
+		}
+
+		switch marker := frame[i+1]; marker {
+		case 0xc4: // DHT
+			return frame
+		case 0xda: // SOS
+			dht := standardHuffmanTables()
+			out := make([]byte, 0, len(frame)+len(dht))
+			out = append(out, frame[:i]...)
+			out = append(out, dht...)
+			return append(out, frame[i:]...)
+		}
+
+		i += 2 + (int(frame[i+2])<<8 | int(frame[i+3]))
+	}
+
+	return frame
+}
+
+var (
+	dhtOnce sync.Once
+	dht     []byte
+)
+
+// standardHuffmanTables returns a DHT segment holding the standard tables
+// from section K.3 of the JPEG specification. They are taken from the output
+// of the image/jpeg encoder, which always writes exactly these tables.
+func standardHuffmanTables() []byte {
+	dhtOnce.Do(func() {
+		var buf bytes.Buffer
+		// Color images get both the luminance and the chrominance tables
+		if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil); err != nil {
+			return
+		}
+
+		b := buf.Bytes()
+		for i := 2; i+4 <= len(b) && b[i] == 0xff; {
+			length := int(b[i+2])<<8 | int(b[i+3])
+			if b[i+1] == 0xc4 {
+				dht = append([]byte(nil), b[i:i+2+length]...)
+				return
+			}
+			i += 2 + length
+		}
+	})
+
+	return dht
+}
@@ -0,0 +1,141 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func TestDecodeYUYV(t *testing.T) {
+	// Two pixel pairs: limited range white and black, both without chroma
+	buf := []byte{
+		235, 128, 235, 128,
+		16, 128, 16, 128,
+	}
+
+	img, err := decodeYUYV(buf, 4, 1, 8)
+	if err != nil {
+		t.Fatalf("decodeYUYV returned error: %v", err)
+	}
+
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected white at (0,0), got %v", got)
+	}
+
+	if got := img.RGBAAt(3, 0); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected black at (3,0), got %v", got)
+	}
+}
+
+func TestDecodeYUYV_ShortFrame(t *testing.T) {
+	if _, err := decodeYUYV(make([]byte, 10), 4, 2, 8); err == nil {
+		t.Error("Expected error for short frame, got none")
+	}
+
+	if _, err := decodeYUYV(make([]byte, 30), 3, 2, 6); err == nil {
+		t.Error("Expected error for odd width, got none")
+	}
+
+	// The last row needs no padding, but every row before it does
+	if _, err := decodeYUYV(make([]byte, 23), 4, 2, 16); err == nil {
+		t.Error("Expected error for short padded frame, got none")
+	}
+}
+
+func TestDecodeYUYV_Stride(t *testing.T) {
+	// Two rows of a white and a black pixel pair, padded to 12 bytes, the
+	// last row without padding
+	buf := []byte{
+		235, 128, 235, 128, 16, 128, 16, 128, 0, 0, 0, 0,
+		16, 128, 16, 128, 235, 128, 235, 128,
+	}
+
+	img, err := decodeYUYV(buf, 4, 2, 12)
+	if err != nil {
+		t.Fatalf("decodeYUYV returned error: %v", err)
+	}
+
+	black, white := color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}
+	for _, tt := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{0, 0, white}, {3, 0, black},
+		{0, 1, black}, {3, 1, white},
+	} {
+		if got := img.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("Expected %v at (%d,%d), got %v", tt.want, tt.x, tt.y, got)
+		}
+	}
+}
+
+func TestBGRToRGBA(t *testing.T) {
+	// Two rows of two pixels, padded to a stride of 8 bytes
+	src := []byte{
+		1, 2, 3, 4, 5, 6, 0, 0,
+		7, 8, 9, 10, 11, 12, 0, 0,
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	bgrToRGBA(img, src, 8)
+
+	want := []byte{
+		3, 2, 1, 255, 6, 5, 4, 255,
+		9, 8, 7, 255, 12, 11, 10, 255,
+	}
+	if !bytes.Equal(img.Pix, want) {
+		t.Errorf("Expected %v, got %v", want, img.Pix)
+	}
+}
+
+// stripHuffmanTables removes all DHT segments from a JPEG, the way many
+// webcams send their MJPEG frames.
+func stripHuffmanTables(frame []byte) []byte {
+	out := append([]byte(nil), frame[:2]...)
+	i := 2
+	for i+4 <= len(frame) && frame[i] == 0xff && frame[i+1] != 0xda {
+		length := int(frame[i+2])<<8 | int(frame[i+3])
+		if frame[i+1] != 0xc4 {
+			out = append(out, frame[i:i+2+length]...)
+		}
+		i += 2 + length
+	}
+	return append(out, frame[i:]...)
+}
+
+func TestDecodeMJPEG(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for i := range src.Pix {
+		src.Pix[i] = 200
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, nil); err != nil {
+		t.Fatalf("jpeg.Encode returned error: %v", err)
+	}
+
+	frame := stripHuffmanTables(buf.Bytes())
+	if bytes.Contains(frame, []byte{0xff, 0xc4}) {
+		t.Fatal("Expected Huffman tables to be stripped")
+	}
+
+	img, err := decodeMJPEG(frame)
+	if err != nil {
+		t.Fatalf("decodeMJPEG returned error: %v", err)
+	}
+
+	if img.Bounds() != src.Bounds() {
+		t.Errorf("Expected bounds %v, got %v", src.Bounds(), img.Bounds())
+	}
+
+	if got := img.RGBAAt(8, 8); got.R < 195 || got.R > 205 {
+		t.Errorf("Expected gray near 200, got %v", got)
+	}
+}
+
+func TestDecodeMJPEG_Invalid(t *testing.T) {
+	if _, err := decodeMJPEG([]byte("not a jpeg")); err == nil {
+		t.Error("Expected error for invalid data, got none")
+	}
+}
@@ -0,0 +1,38 @@
+package camera
+
+// Device describes a capture device found by ListDevices.
+type Device struct {
+	ID      int            `json:"id"`
+	Path    string         `json:"path"`
+	Name    string         `json:"name,omitempty"`
+	Driver  string         `json:"driver,omitempty"`
+	Bus     string         `json:"bus,omitempty"`
+	Formats []DeviceFormat `json:"formats,omitempty"`
+	Error   string         `json:"error,omitempty"` // set if the device could not be queried
+}
+
+// DeviceFormat is a pixel format supported by a device, together with the
+// frame sizes it can deliver in that format.
+type DeviceFormat struct {
+	PixelFormat string      `json:"pixel_format"`
+	Description string      `json:"description,omitempty"`
+	Sizes       []FrameSize `json:"sizes,omitempty"`
+}
+
+// FrameSize is a frame size a device supports and the frame rates it can
+// capture at in that size. Devices with a continuous range of sizes are
+// reported by their smallest and largest size.
+type FrameSize struct {
+	Width      uint      `json:"width"`
+	Height     uint      `json:"height"`
+	FrameRates []float64 `json:"frame_rates,omitempty"`
+}
+
+// frameRate converts a frame interval in seconds, given as a fraction, to a
+// frame rate.
+func frameRate(num, den uint32) float64 {
+	if num == 0 {
+		return 0
+	}
+	return float64(den) / float64(num)
+}
@@ -0,0 +1,174 @@
+package camera
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// videoDevicePrefix is the path prefix of V4L2 device nodes; the device
+	// ID follows it.
+	videoDevicePrefix = "/dev/video"
+	// stableDeviceDir holds symlinks to device nodes named after the physical
+	// camera, which stay valid when the camera is replugged and its device
+	// ID changes.
+	stableDeviceDir = "/dev/v4l/by-id"
+)
+
+// ListDevices probes all V4L2 device nodes and returns the ones that can
+// capture video, sorted by device ID. Nodes that exist but cannot be opened
+// are included with their Error set, so permission problems are visible.
+func ListDevices() ([]Device, error) {
+	paths, err := filepath.Glob(videoDevicePrefix + "*")
+	if err != nil {
+		return nil, errors.NewFileError(videoDevicePrefix, "list", fmt.Errorf("%w: %v", errors.ErrFileReadFailed, err))
+	}
+
+	devices := []Device{}
+	for _, path := range paths {
+		id, err := strconv.Atoi(strings.TrimPrefix(path, videoDevicePrefix))
+		if err != nil {
+			continue
+		}
+		if d, ok := probeDevice(id, path); ok {
+			devices = append(devices, d)
+		}
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].ID < devices[j].ID
+	})
+	return devices, nil
+}
+
+// probeDevice queries the capabilities and formats of a device node. It
+// reports false for nodes that cannot capture video, such as the metadata
+// nodes UVC cameras register next to their video node.
+func probeDevice(id int, path string) (Device, bool) {
+	d := Device{ID: id, Path: path}
+
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		d.Error = err.Error()
+		return d, true
+	}
+	defer unix.Close(fd)
+
+	capability, err := queryCapability(fd)
+	if err != nil {
+		d.Error = err.Error()
+		return d, true
+	}
+	if capability.caps()&v4l2CapVideoCapture == 0 {
+		return d, false
+	}
+
+	d.Name = cString(capability.Card[:])
+	d.Driver = cString(capability.Driver[:])
+	d.Bus = cString(capability.BusInfo[:])
+	d.Formats = enumFormats(fd)
+	return d, true
+}
+
+// enumFormats lists the capture pixel formats of the device open on fd.
+func enumFormats(fd int) []DeviceFormat {
+	var formats []DeviceFormat
+	for i := uint32(0); ; i++ {
+		desc := v4l2FmtDesc{Index: i, Type: v4l2BufTypeVideoCapture}
+		if err := ioctl(fd, vidiocEnumFmt, unsafe.Pointer(&desc)); err != nil {
+			return formats
+		}
+
+		formats = append(formats, DeviceFormat{
+			PixelFormat: fourCC(desc.PixelFormat),
+			Description: cString(desc.Description[:]),
+			Sizes:       enumFrameSizes(fd, desc.PixelFormat),
+		})
+	}
+}
+
+// enumFrameSizes lists the frame sizes the device supports for pixelFormat.
+func enumFrameSizes(fd int, pixelFormat uint32) []FrameSize {
+	var sizes []FrameSize
+	for i := uint32(0); ; i++ {
+		fs := v4l2FrmSizeEnum{Index: i, PixelFormat: pixelFormat}
+		if err := ioctl(fd, vidiocEnumFrameSizes, unsafe.Pointer(&fs)); err != nil {
+			return sizes
+		}
+
+		if fs.Type == v4l2FrmSizeTypeDiscrete {
+			w, h := fs.Size[0], fs.Size[1]
+			sizes = append(sizes, FrameSize{
+				Width:      uint(w),
+				Height:     uint(h),
+				FrameRates: enumFrameRates(fd, pixelFormat, w, h),
+			})
+			continue
+		}
+
+		// Stepwise and continuous ranges are reported by their bounds
+		minW, maxW, minH, maxH := fs.Size[0], fs.Size[1], fs.Size[3], fs.Size[4]
+		return append(sizes,
+			FrameSize{Width: uint(minW), Height: uint(minH), FrameRates: enumFrameRates(fd, pixelFormat, minW, minH)},
+			FrameSize{Width: uint(maxW), Height: uint(maxH), FrameRates: enumFrameRates(fd, pixelFormat, maxW, maxH)},
+		)
+	}
+}
+
+// enumFrameRates lists the frame rates the device supports for pixelFormat
+// at the given size, fastest first for discrete intervals.
+func enumFrameRates(fd int, pixelFormat, width, height uint32) []float64 {
+	var rates []float64
+	for i := uint32(0); ; i++ {
+		fi := v4l2FrmIvalEnum{Index: i, PixelFormat: pixelFormat, Width: width, Height: height}
+		if err := ioctl(fd, vidiocEnumFrameIntervals, unsafe.Pointer(&fi)); err != nil {
+			return rates
+		}
+
+		if fi.Type == v4l2FrmIvalTypeDiscrete {
+			if rate := frameRate(fi.Interval[0], fi.Interval[1]); rate > 0 {
+				rates = append(rates, rate)
+			}
+			continue
+		}
+
+		// The shortest interval is the highest frame rate and vice versa
+		return append(rates, frameRate(fi.Interval[0], fi.Interval[1]), frameRate(fi.Interval[2], fi.Interval[3]))
+	}
+}
+
+// StableDevicePath returns the /dev/v4l/by-id link pointing at the camera
+// with the given device ID, if udev created one.
+func StableDevicePath(deviceID int) (string, bool) {
+	links, _ := filepath.Glob(filepath.Join(stableDeviceDir, "*"))
+	want := fmt.Sprintf("%s%d", videoDevicePrefix, deviceID)
+	for _, link := range links {
+		if target, err := filepath.EvalSymlinks(link); err == nil && target == want {
+			return link, true
+		}
+	}
+	return "", false
+}
+
+// DeviceIDFromPath returns the device ID of the V4L2 device node at path,
+// following symlinks such as those in /dev/v4l/by-id.
+func DeviceIDFromPath(path string) (int, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return 0, errors.NewFileError(path, "resolve", fmt.Errorf("%w: %v", errors.ErrCameraNotFound, err))
+	}
+
+	suffix, ok := strings.CutPrefix(target, videoDevicePrefix)
+	id, err := strconv.Atoi(suffix)
+	if !ok || err != nil {
+		return 0, errors.NewFileError(path, "resolve", fmt.Errorf("%w: %s is not a video device", errors.ErrCameraUnsupported, target))
+	}
+	return id, nil
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package camera
+
+import (
+	"fmt"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// ListDevices is only implemented for Linux, where devices can be probed
+// through V4L2.
+func ListDevices() ([]Device, error) {
+	return nil, fmt.Errorf("%w: device listing is only available on Linux", errors.ErrCameraUnsupported)
+}
+
+// StableDevicePath always reports false; stable device paths are a Linux
+// feature.
+func StableDevicePath(int) (string, bool) {
+	return "", false
+}
+
+// DeviceIDFromPath is only implemented for Linux.
+func DeviceIDFromPath(path string) (int, error) {
+	return 0, errors.NewFileError(path, "resolve", fmt.Errorf("%w: device paths are only available on Linux", errors.ErrCameraUnsupported))
+}
@@ -0,0 +1,78 @@
+//go:build cgo && !noopencv
+
+package camera
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"gocv.io/x/gocv"
+)
+
+// CascadeFaceDetector finds faces with an OpenCV Haar cascade classifier,
+// such as haarcascade_frontalface_default.xml shipped with OpenCV.
+type CascadeFaceDetector struct {
+	classifier gocv.CascadeClassifier
+	gray       []byte // reused grayscale conversion buffer
+}
+
+var _ FaceDetector = (*CascadeFaceDetector)(nil)
+
+// NewFaceDetector loads the cascade classifier stored at path.
+func NewFaceDetector(path string) (*CascadeFaceDetector, error) {
+	classifier := gocv.NewCascadeClassifier()
+	if !classifier.Load(path) {
+		classifier.Close()
+		return nil, errors.NewFileError(path, "load", fmt.Errorf("%w: not a cascade classifier", errors.ErrFileReadFailed))
+	}
+	return &CascadeFaceDetector{classifier: classifier}, nil
+}
+
+// DetectFace returns the bounds of the largest face in img.
+func (d *CascadeFaceDetector) DetectFace(img image.Image) (image.Rectangle, bool) {
+	b := img.Bounds()
+	if b.Empty() {
+		return image.Rectangle{}, false
+	}
+
+	// The classifier works on grayscale images
+	d.gray = d.gray[:0]
+	if rgba, ok := img.(*image.RGBA); ok {
+		for y := 0; y < b.Dy(); y++ {
+			row := rgba.Pix[y*rgba.Stride : y*rgba.Stride+b.Dx()*4]
+			for i := 0; i < len(row); i += 4 {
+				d.gray = append(d.gray, uint8((299*int(row[i])+587*int(row[i+1])+114*int(row[i+2]))/1000))
+			}
+		}
+	} else {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				r, g, bl, _ := img.At(x, y).RGBA()
+				d.gray = append(d.gray, uint8((299*r+587*g+114*bl)/1000>>8))
+			}
+		}
+	}
+
+	mat, err := gocv.NewMatFromBytes(b.Dy(), b.Dx(), gocv.MatTypeCV8UC1, d.gray)
+	if err != nil {
+		return image.Rectangle{}, false
+	}
+	defer mat.Close()
+
+	var face image.Rectangle
+	for _, r := range d.classifier.DetectMultiScale(mat) {
+		if r.Dx()*r.Dy() > face.Dx()*face.Dy() {
+			face = r
+		}
+	}
+	if face.Empty() {
+		return image.Rectangle{}, false
+	}
+	return face.Add(b.Min), true
+}
+
+// Close releases the classifier.
+func (d *CascadeFaceDetector) Close() {
+	d.classifier.Close()
+}
@@ -0,0 +1,21 @@
+//go:build cgo && !noopencv
+
+package camera
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestNewFaceDetector_InvalidCascade(t *testing.T) {
+	_, err := NewFaceDetector(filepath.Join(t.TempDir(), "missing.xml"))
+	if err == nil {
+		t.Fatal("Expected error for missing cascade file, got none")
+	}
+
+	if _, ok := err.(*errors.FileError); !ok {
+		t.Errorf("Expected *errors.FileError, got %T", err)
+	}
+}
@@ -0,0 +1,298 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// defaultGIFDelay is used for GIF frames that do not specify a delay.
+const defaultGIFDelay = 100 * time.Millisecond
+
+// imageExtensions lists the file extensions handled by ImageSequence.
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+}
+
+// IsImageInput reports whether path refers to a still image or a glob
+// pattern of images rather than a video file.
+func IsImageInput(path string) bool {
+	if isGlob(path) {
+		return true
+	}
+	return imageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// isGlob reports whether path is a glob pattern rather than the name of a
+// file. Files whose names contain pattern characters, such as clip[1].mp4,
+// are taken by their name.
+func isGlob(path string) bool {
+	if !strings.ContainsAny(path, "*?[") {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err != nil
+}
+
+// ImageSequence plays back still images, animated GIFs or a sequence of
+// numbered images such as the background samples written by
+// greenscreen.GenerateSamples. Images are decoded lazily, one file at a time.
+type ImageSequence struct {
+	paths    []string
+	interval time.Duration
+	loop     bool
+	width    uint
+	height   uint
+
+	index  int             // index of the next file to load
+	frames []image.Image   // frames of the current file
+	delays []time.Duration // per-frame delays of the current file
+	frame  int             // index of the next frame to show
+
+	next   time.Time
+	paused bool
+	last   image.Image
+}
+
+var (
+	_ FrameSource = (*ImageSequence)(nil)
+	_ Pauser      = (*ImageSequence)(nil)
+)
+
+// NewImageSequence opens a single image or all images matching a glob
+// pattern, ordered by the numbers in their names. Each still image is shown
+// for interval; animated GIFs use their own frame delays. A single still
+// image is shown until the source is closed; otherwise playback ends after
+// the last image unless loop is true.
+func NewImageSequence(pattern string, interval time.Duration, loop bool) (*ImageSequence, error) {
+	paths := []string{pattern}
+	var err error
+	if isGlob(pattern) {
+		paths, err = filepath.Glob(pattern)
+	}
+	if err != nil {
+		return nil, errors.NewFileError(pattern, "glob", fmt.Errorf("%w: %v", errors.ErrFileNotFound, err))
+	}
+	if len(paths) == 0 {
+		return nil, errors.NewFileError(pattern, "glob", errors.ErrFileNotFound)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return naturalLess(paths[i], paths[j])
+	})
+
+	s := &ImageSequence{
+		paths:    paths,
+		interval: interval,
+		loop:     loop,
+	}
+	if err := s.load(0); err != nil {
+		return nil, err
+	}
+
+	b := s.frames[0].Bounds()
+	s.width, s.height = uint(b.Dx()), uint(b.Dy())
+
+	// A lone still image never ends
+	if len(paths) == 1 && len(s.frames) == 1 {
+		s.loop = true
+	}
+
+	return s, nil
+}
+
+// Close releases the decoded frames.
+func (s *ImageSequence) Close() {
+	s.frames = nil
+	s.last = nil
+}
+
+// Dimensions returns the width and height of the first image.
+func (s *ImageSequence) Dimensions() (uint, uint) {
+	return s.width, s.height
+}
+
+// TogglePause pauses or resumes the slideshow and reports whether it is now paused.
+func (s *ImageSequence) TogglePause() bool {
+	s.paused = !s.paused
+	if !s.paused {
+		s.next = time.Time{}
+	}
+	return s.paused
+}
+
+// ReadFrameWithContext returns the next image once the previous one has been
+// shown for its delay. Once the sequence is exhausted without looping, an
+// error wrapping errors.ErrEndOfStream is returned.
+func (s *ImageSequence) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	if d := time.Until(s.next); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	if s.paused && s.last != nil {
+		s.next = time.Now().Add(s.interval)
+		return s.last, nil
+	}
+
+	if s.frame >= len(s.frames) {
+		switch {
+		case s.index < len(s.paths):
+			if err := s.load(s.index); err != nil {
+				return nil, err
+			}
+		case !s.loop:
+			return nil, errors.NewFileError(s.paths[len(s.paths)-1], "read", errors.ErrEndOfStream)
+		case len(s.paths) == 1:
+			// Replay the frames already decoded
+			s.frame = 0
+		default:
+			if err := s.load(0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.last = s.frames[s.frame]
+	s.next = time.Now().Add(s.delays[s.frame])
+	s.frame++
+
+	return s.last, nil
+}
+
+// load decodes the file at index i into frames and advances to the next file.
+func (s *ImageSequence) load(i int) error {
+	path := s.paths[i]
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.NewFileError(path, "open", fmt.Errorf("%w: %v", errors.ErrFileReadFailed, err))
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".gif") {
+		g, err := gif.DecodeAll(f)
+		if err != nil {
+			return errors.NewFileError(path, "decode", fmt.Errorf("%w: %v", errors.ErrImageDecodeFailed, err))
+		}
+		s.frames, s.delays = composeGIF(g, s.interval)
+	} else {
+		img, _, err := image.Decode(f)
+		if err != nil {
+			return errors.NewFileError(path, "decode", fmt.Errorf("%w: %v", errors.ErrImageDecodeFailed, err))
+		}
+		s.frames = []image.Image{img}
+		s.delays = []time.Duration{s.interval}
+	}
+
+	s.index = i + 1
+	s.frame = 0
+	return nil
+}
+
+// composeGIF renders the frames of an animated GIF onto a full-size canvas,
+// honoring each frame's disposal method. Single-frame GIFs are treated as
+// still images and shown for interval.
+func composeGIF(g *gif.GIF, interval time.Duration) ([]image.Image, []time.Duration) {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+
+	canvas := image.NewRGBA(bounds)
+	frames := make([]image.Image, 0, len(g.Image))
+	delays := make([]time.Duration, 0, len(g.Image))
+
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		out := image.NewRGBA(bounds)
+		copy(out.Pix, canvas.Pix)
+		frames = append(frames, out)
+
+		delay := interval
+		if len(g.Image) > 1 {
+			delay = defaultGIFDelay
+			if i < len(g.Delay) && g.Delay[i] > 0 {
+				delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+			}
+		}
+		delays = append(delays, delay)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return frames, delays
+}
+
+// naturalLess compares two strings, ordering runs of digits by their numeric
+// value so that "2.png" sorts before "10.png".
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := digitPrefix(a), digitPrefix(b)
+		if da != "" && db != "" {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+
+	return len(a) < len(b)
+}
+
+// digitPrefix returns the leading run of ASCII digits in s.
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
@@ -0,0 +1,197 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// writePNG writes a solid w x h PNG filled with c.
+func writePNG(t *testing.T, path string, w, h int, c color.Color) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsImageInput(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"photo.png", true},
+		{"photo.JPG", true},
+		{"photo.jpeg", true},
+		{"anim.gif", true},
+		{"bgsample/*.png", true},
+		{"clip.mp4", false},
+		{"clip", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsImageInput(tt.path); got != tt.expected {
+			t.Errorf("IsImageInput(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestIsImageInput_LiteralName(t *testing.T) {
+	dir := t.TempDir()
+
+	// Existing files are taken by their name, even with pattern characters
+	video := filepath.Join(dir, "clip[1].mp4")
+	if err := os.WriteFile(video, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if IsImageInput(video) {
+		t.Errorf("Expected %q to be a video", video)
+	}
+
+	still := filepath.Join(dir, "photo[1].png")
+	writePNG(t, still, 4, 2, color.White)
+	s, err := NewImageSequence(still, time.Second, false)
+	if err != nil {
+		t.Fatalf("NewImageSequence returned error for %q: %v", still, err)
+	}
+	s.Close()
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"2.png", "10.png", true},
+		{"10.png", "2.png", false},
+		{"bg/9.png", "bg/40.png", true},
+		{"a.png", "b.png", true},
+		{"007.png", "7.png", false},
+		{"frame", "frame1", true},
+	}
+
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.expected {
+			t.Errorf("naturalLess(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestImageSequence_NumberedOrder(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []int{10, 2, 1} {
+		writePNG(t, filepath.Join(dir, fmt.Sprintf("%d.png", n)), n, 4, color.White)
+	}
+
+	seq, err := NewImageSequence(filepath.Join(dir, "*.png"), 0, false)
+	if err != nil {
+		t.Fatalf("NewImageSequence returned error: %v", err)
+	}
+	defer seq.Close()
+
+	if w, h := seq.Dimensions(); w != 1 || h != 4 {
+		t.Errorf("Expected dimensions of first image 1x4, got %dx%d", w, h)
+	}
+
+	// Image widths encode the file number
+	for _, expected := range []int{1, 2, 10} {
+		img, err := seq.ReadFrameWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("ReadFrameWithContext returned error: %v", err)
+		}
+		if img.Bounds().Dx() != expected {
+			t.Errorf("Expected image %d, got width %d", expected, img.Bounds().Dx())
+		}
+	}
+
+	_, err = seq.ReadFrameWithContext(context.Background())
+	if !errors.IsEndOfStream(err) {
+		t.Errorf("Expected end of stream, got %v", err)
+	}
+}
+
+func TestImageSequence_SingleImageRepeats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "still.png")
+	writePNG(t, path, 8, 6, color.Black)
+
+	seq, err := NewImageSequence(path, 0, false)
+	if err != nil {
+		t.Fatalf("NewImageSequence returned error: %v", err)
+	}
+	defer seq.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := seq.ReadFrameWithContext(context.Background()); err != nil {
+			t.Fatalf("Read %d returned error: %v", i, err)
+		}
+	}
+}
+
+func TestImageSequence_NotFound(t *testing.T) {
+	_, err := NewImageSequence(filepath.Join(t.TempDir(), "*.png"), 0, false)
+	if err == nil {
+		t.Error("Expected error for pattern without matches, got none")
+	}
+}
+
+func TestComposeGIF(t *testing.T) {
+	palette := color.Palette{color.Transparent, color.White, color.Black}
+
+	full := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	for i := range full.Pix {
+		full.Pix[i] = 1 // white
+	}
+	patch := image.NewPaletted(image.Rect(1, 1, 2, 2), palette)
+	patch.Pix[0] = 2 // black
+
+	g := &gif.GIF{
+		Image:    []*image.Paletted{full, patch},
+		Delay:    []int{5, 0},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalNone},
+		Config:   image.Config{Width: 4, Height: 4},
+	}
+
+	frames, delays := composeGIF(g, time.Second)
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(frames))
+	}
+
+	if delays[0] != 50*time.Millisecond {
+		t.Errorf("Expected 50ms delay, got %v", delays[0])
+	}
+	if delays[1] != defaultGIFDelay {
+		t.Errorf("Expected default delay for zero delay frame, got %v", delays[1])
+	}
+
+	// The second frame only updates one pixel on top of the first
+	r, _, _, _ := frames[1].At(0, 0).RGBA()
+	if r != 0xffff {
+		t.Error("Expected pixel from the first frame to be kept")
+	}
+	r, _, _, _ = frames[1].At(1, 1).RGBA()
+	if r != 0 {
+		t.Error("Expected patched pixel to be black")
+	}
+}
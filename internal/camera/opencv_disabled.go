@@ -0,0 +1,31 @@
+//go:build !cgo || noopencv
+
+package camera
+
+import (
+	"fmt"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// OpenCVAvailable reports whether this binary was built with OpenCV support.
+const OpenCVAvailable = false
+
+// errNoOpenCV is returned by the OpenCV-backed sources in builds without OpenCV.
+var errNoOpenCV = fmt.Errorf("%w: built without OpenCV support", errors.ErrCameraUnsupported)
+
+// NewCapture always fails in builds without OpenCV; use NewV4L2Capture instead.
+func NewCapture(deviceID int, _, _ uint) (FrameSource, error) {
+	return nil, errors.NewCameraError(deviceID, "open", errNoOpenCV)
+}
+
+// NewVideoFile always fails in builds without OpenCV; pipe the video through
+// ffmpeg into a RawStream instead.
+func NewVideoFile(path string, _ bool) (FrameSource, error) {
+	return nil, errors.NewFileError(path, "open", errNoOpenCV)
+}
+
+// NewFaceDetector always fails in builds without OpenCV.
+func NewFaceDetector(path string) (FaceDetector, error) {
+	return nil, errors.NewFileError(path, "load", errNoOpenCV)
+}
@@ -0,0 +1,60 @@
+package camera
+
+import (
+	"image"
+	"sync"
+)
+
+// FramePool recycles frame buffers between a source and the renderer, which
+// saves allocating several megabytes per frame at camera resolutions. A nil
+// FramePool is valid and allocates every frame.
+type FramePool struct {
+	mu   sync.Mutex
+	free []*image.RGBA
+	size int
+}
+
+// NewFramePool creates a pool keeping up to size unused frames.
+func NewFramePool(size int) *FramePool {
+	return &FramePool{size: size}
+}
+
+// Get returns an image with the given bounds. Its pixels are not cleared.
+func (p *FramePool) Get(r image.Rectangle) *image.RGBA {
+	if p != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		// Rotated frames share the pool with unrotated ones, so look past
+		// frames of another size
+		for i := len(p.free) - 1; i >= 0; i-- {
+			if img := p.free[i]; img.Rect == r {
+				p.free = append(p.free[:i], p.free[i+1:]...)
+				return img
+			}
+		}
+	}
+
+	return image.NewRGBA(r)
+}
+
+// Put hands a frame back to the pool once the caller is done with it. The
+// frame must not be used afterwards.
+func (p *FramePool) Put(img image.Image) {
+	rgba, ok := img.(*image.RGBA)
+	if p == nil || !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.size <= 0 {
+		return
+	}
+	// Drop the oldest frame when full, so frames of a size no longer in use
+	// do not keep newer ones out
+	if len(p.free) == p.size {
+		p.free = append(p.free[:0], p.free[1:]...)
+	}
+	p.free = append(p.free, rgba)
+}
@@ -0,0 +1,60 @@
+package camera
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFramePool(t *testing.T) {
+	pool := NewFramePool(1)
+	r := image.Rect(0, 0, 4, 4)
+
+	img := pool.Get(r)
+	pool.Put(img)
+	if got := pool.Get(r); got != img {
+		t.Error("Expected the returned frame to be reused")
+	}
+
+	// Frames of another size are not handed out, but stay in the pool
+	pool.Put(img)
+	if got := pool.Get(image.Rect(0, 0, 8, 8)); got == img || got.Rect.Dx() != 8 {
+		t.Error("Expected a new frame of the requested size")
+	}
+	if got := pool.Get(r); got != img {
+		t.Error("Expected the frame of the other size to be kept")
+	}
+
+	// The pool keeps the newest size frames
+	a, b := pool.Get(r), pool.Get(r)
+	pool.Put(a)
+	pool.Put(b)
+	if got := pool.Get(r); got != b {
+		t.Error("Expected the last returned frame to be kept")
+	}
+}
+
+func TestFramePool_MixedSizes(t *testing.T) {
+	pool := NewFramePool(2)
+	wide, tall := image.Rect(0, 0, 8, 4), image.Rect(0, 0, 4, 8)
+
+	a, b := pool.Get(wide), pool.Get(tall)
+	pool.Put(a)
+	pool.Put(b)
+
+	if got := pool.Get(wide); got != a {
+		t.Error("Expected the wide frame to be reused")
+	}
+	if got := pool.Get(tall); got != b {
+		t.Error("Expected the tall frame to be reused")
+	}
+}
+
+func TestFramePool_Nil(t *testing.T) {
+	var pool *FramePool
+
+	img := pool.Get(image.Rect(0, 0, 2, 2))
+	if img == nil || img.Rect.Dx() != 2 {
+		t.Fatal("Expected nil pool to allocate frames")
+	}
+	pool.Put(img)
+}
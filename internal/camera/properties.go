@@ -0,0 +1,123 @@
+package camera
+
+import "math"
+
+// Property is an adjustable camera setting such as exposure or focus.
+type Property string
+
+// Camera properties. Values are in the driver's own units, except for the
+// Auto* properties, which are 1 for on and 0 for off, and FPS.
+const (
+	PropertyAutoExposure     Property = "autoExposure"
+	PropertyExposure         Property = "exposure"
+	PropertyGain             Property = "gain"
+	PropertyAutoFocus        Property = "autoFocus"
+	PropertyFocus            Property = "focus"
+	PropertyBrightness       Property = "brightness"
+	PropertyContrast         Property = "contrast"
+	PropertySaturation       Property = "saturation"
+	PropertyAutoWhiteBalance Property = "autoWhiteBalance"
+	PropertyWhiteBalance     Property = "whiteBalance" // color temperature in Kelvin
+	PropertyFPS              Property = "fps"
+)
+
+// propertyOrder is the order properties are applied in. Automatic modes go
+// first, as most drivers ignore manual values while the automatic mode is on.
+var propertyOrder = []Property{
+	PropertyAutoExposure,
+	PropertyAutoFocus,
+	PropertyAutoWhiteBalance,
+	PropertyFPS,
+	PropertyExposure,
+	PropertyGain,
+	PropertyFocus,
+	PropertyBrightness,
+	PropertyContrast,
+	PropertySaturation,
+	PropertyWhiteBalance,
+}
+
+// manualProperties maps properties to the automatic mode that must be off
+// for them to take effect.
+var manualProperties = map[Property]Property{
+	PropertyExposure:     PropertyAutoExposure,
+	PropertyFocus:        PropertyAutoFocus,
+	PropertyWhiteBalance: PropertyAutoWhiteBalance,
+}
+
+// Properties maps camera properties to requested values. Properties that are
+// not in the map are left at the driver's current setting.
+type Properties map[Property]float64
+
+// resolve returns the properties to apply and the order to apply them in.
+// A manual value implies turning its automatic mode off, unless the automatic
+// mode was requested explicitly.
+func (p Properties) resolve() (Properties, []Property) {
+	resolved := make(Properties, len(p))
+	for prop, value := range p {
+		resolved[prop] = value
+	}
+	for prop, auto := range manualProperties {
+		if _, ok := p[prop]; ok {
+			if _, ok := p[auto]; !ok {
+				resolved[auto] = 0
+			}
+		}
+	}
+
+	var order []Property
+	for _, prop := range propertyOrder {
+		if _, ok := resolved[prop]; ok {
+			order = append(order, prop)
+		}
+	}
+	return resolved, order
+}
+
+// PropertyResult reports the value the driver settled on for a requested
+// property.
+type PropertyResult struct {
+	Property  Property
+	Requested float64
+	Actual    float64
+	Err       error // set if the driver rejected the property outright
+}
+
+// Ignored reports whether the camera did not apply the requested value.
+func (r PropertyResult) Ignored() bool {
+	if r.Err != nil {
+		return true
+	}
+	// Drivers round to their step size, so allow for small differences
+	return math.Abs(r.Actual-r.Requested) > math.Max(math.Abs(r.Requested)*0.01, 0.5)
+}
+
+// PropertySetter is implemented by sources whose camera properties can be
+// adjusted.
+type PropertySetter interface {
+	// SetProperties applies the given properties and reports the values
+	// the driver accepted for each of them.
+	SetProperties(props Properties) []PropertyResult
+}
+
+// V4L2 exposure modes. OpenCV passes the mode through unchanged on Linux.
+const (
+	exposureManual           = 1
+	exposureAperturePriority = 3
+)
+
+// exposureMode converts an auto exposure on/off value to a V4L2 exposure mode.
+func exposureMode(auto float64) float64 {
+	if auto != 0 {
+		return exposureAperturePriority
+	}
+	return exposureManual
+}
+
+// autoExposure converts a V4L2 exposure mode back to an on/off value.
+func autoExposure(mode float64) float64 {
+	if mode == exposureManual {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,64 @@
+package camera
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProperties_Resolve(t *testing.T) {
+	requested := Properties{
+		PropertyBrightness: 10,
+		PropertyExposure:   200,
+		PropertyFocus:      30,
+		PropertyAutoFocus:  1,
+	}
+
+	props, order := requested.resolve()
+
+	// A manual exposure turns auto exposure off, an explicit autofocus wins
+	if v, ok := props[PropertyAutoExposure]; !ok || v != 0 {
+		t.Errorf("Expected autoExposure 0, got %v (set: %v)", v, ok)
+	}
+	if props[PropertyAutoFocus] != 1 {
+		t.Errorf("Expected autoFocus 1, got %v", props[PropertyAutoFocus])
+	}
+	if _, ok := requested[PropertyAutoExposure]; ok {
+		t.Error("resolve must not modify the requested properties")
+	}
+
+	want := []Property{PropertyAutoExposure, PropertyAutoFocus, PropertyExposure, PropertyFocus, PropertyBrightness}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+}
+
+func TestPropertyResult_Ignored(t *testing.T) {
+	tests := []struct {
+		name   string
+		result PropertyResult
+		want   bool
+	}{
+		{"applied", PropertyResult{Requested: 200, Actual: 200}, false},
+		{"rounded", PropertyResult{Requested: 4500, Actual: 4510}, false},
+		{"small rounded", PropertyResult{Requested: 0.5, Actual: 0}, false},
+		{"clamped", PropertyResult{Requested: 5000, Actual: 2000}, true},
+		{"switch", PropertyResult{Requested: 1, Actual: 0}, true},
+		{"rejected", PropertyResult{Requested: 1, Actual: 1, Err: fmt.Errorf("EINVAL")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Ignored(); got != tt.want {
+				t.Errorf("Ignored() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExposureMode(t *testing.T) {
+	for _, auto := range []float64{0, 1} {
+		if got := autoExposure(exposureMode(auto)); got != auto {
+			t.Errorf("Expected auto exposure %v to round trip, got %v", auto, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+const (
+	// maxReadFailures is the number of consecutive failed reads after which
+	// the camera is considered gone and reopened.
+	maxReadFailures = 5
+	// reconnectMinDelay is the wait before the first attempt to reopen.
+	reconnectMinDelay = 250 * time.Millisecond
+	// reconnectMaxDelay caps the exponential backoff between attempts.
+	reconnectMaxDelay = 5 * time.Second
+)
+
+// ReconnectingSource supervises a camera: when reads keep failing or fail
+// fatally, for example because the camera was unplugged, it closes the camera
+// and reopens it with exponential backoff. While the camera is gone, reads
+// return errors wrapping errors.ErrCameraLost, which are retryable.
+//
+// ReconnectingSource is not safe for concurrent use; wrap it in an
+// AsyncSource to read from a separate goroutine.
+type ReconnectingSource struct {
+	src      FrameSource
+	open     func() (FrameSource, error)
+	deviceID int
+	failures int
+	minDelay time.Duration
+	maxDelay time.Duration
+	delay    time.Duration
+	retryAt  time.Time
+	width    uint
+	height   uint
+}
+
+var _ FrameSource = (*ReconnectingSource)(nil)
+
+// NewReconnectingSource supervises the already opened camera src. open is
+// called to reopen the camera after it was lost.
+func NewReconnectingSource(src FrameSource, deviceID int, open func() (FrameSource, error)) *ReconnectingSource {
+	w, h := src.Dimensions()
+	return &ReconnectingSource{
+		src:      src,
+		open:     open,
+		deviceID: deviceID,
+		minDelay: reconnectMinDelay,
+		maxDelay: reconnectMaxDelay,
+		width:    w,
+		height:   h,
+	}
+}
+
+// ReadFrameWithContext reads a frame from the camera, reopening it first if
+// it was lost.
+func (r *ReconnectingSource) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	if r.src == nil {
+		if err := r.reconnect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	img, err := r.src.ReadFrameWithContext(ctx)
+	if err == nil {
+		r.failures = 0
+		return img, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	if errors.IsRetryable(err) {
+		r.failures++
+		if r.failures < maxReadFailures {
+			return nil, err
+		}
+	} else if !errors.IsFatal(err) {
+		return nil, err
+	}
+
+	// The camera is gone, start over
+	r.disconnect()
+	return nil, errors.NewCameraError(r.deviceID, "read", fmt.Errorf("%w: %v", errors.ErrCameraLost, err))
+}
+
+// disconnect closes the lost camera and schedules the first reconnect.
+func (r *ReconnectingSource) disconnect() {
+	r.width, r.height = r.src.Dimensions()
+	r.src.Close()
+	r.src = nil
+	r.failures = 0
+	r.delay = r.minDelay
+	r.retryAt = time.Now().Add(r.delay)
+}
+
+// reconnect waits out the backoff delay and tries to reopen the camera.
+func (r *ReconnectingSource) reconnect(ctx context.Context) error {
+	timer := time.NewTimer(time.Until(r.retryAt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled: %w", ctx.Err())
+	case <-timer.C:
+	}
+
+	src, err := r.open()
+	if err != nil {
+		r.delay = min(r.delay*2, r.maxDelay)
+		r.retryAt = time.Now().Add(r.delay)
+		return errors.NewCameraError(r.deviceID, "reconnect", fmt.Errorf("%w: %v", errors.ErrCameraLost, err))
+	}
+
+	r.src = src
+	return nil
+}
+
+// Dimensions returns the frame size of the camera, or of the last camera
+// that was open while reconnecting.
+func (r *ReconnectingSource) Dimensions() (uint, uint) {
+	if r.src != nil {
+		return r.src.Dimensions()
+	}
+	return r.width, r.height
+}
+
+// Close closes the camera, if it is open.
+func (r *ReconnectingSource) Close() {
+	if r.src != nil {
+		r.src.Close()
+		r.src = nil
+	}
+}
@@ -0,0 +1,121 @@
+package camera
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// failingSource returns err on every read, or a frame if err is nil.
+type failingSource struct {
+	err    error
+	closed bool
+}
+
+func (f *failingSource) ReadFrameWithContext(context.Context) (image.Image, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return image.NewRGBA(image.Rect(0, 0, 4, 4)), nil
+}
+
+func (f *failingSource) Close() { f.closed = true }
+
+func (f *failingSource) Dimensions() (uint, uint) { return 4, 4 }
+
+// newTestReconnectingSource returns a ReconnectingSource with short delays.
+func newTestReconnectingSource(src FrameSource, open func() (FrameSource, error)) *ReconnectingSource {
+	r := NewReconnectingSource(src, 0, open)
+	r.minDelay = time.Millisecond
+	r.maxDelay = 4 * time.Millisecond
+	return r
+}
+
+func TestReconnectingSource_ReadFailures(t *testing.T) {
+	src := &failingSource{err: errors.NewCameraError(0, "read", errors.ErrCameraReadFailed)}
+	r := newTestReconnectingSource(src, func() (FrameSource, error) {
+		return &failingSource{}, nil
+	})
+	defer r.Close()
+
+	// Occasional failures are passed on unchanged
+	for i := 1; i < maxReadFailures; i++ {
+		_, err := r.ReadFrameWithContext(context.Background())
+		if !errors.IsRetryable(err) || errors.IsCameraLost(err) {
+			t.Fatalf("Read %d: expected plain retryable error, got %v", i, err)
+		}
+	}
+
+	// One more and the camera is considered lost
+	if _, err := r.ReadFrameWithContext(context.Background()); !errors.IsCameraLost(err) {
+		t.Fatalf("Expected camera lost error, got %v", err)
+	}
+	if !src.closed {
+		t.Error("Expected the lost camera to be closed")
+	}
+
+	// The next read reopens the camera
+	if _, err := r.ReadFrameWithContext(context.Background()); err != nil {
+		t.Errorf("Expected frame from the reopened camera, got %v", err)
+	}
+}
+
+func TestReconnectingSource_Backoff(t *testing.T) {
+	opens := 0
+	r := newTestReconnectingSource(&failingSource{err: errors.ErrCameraNotFound}, func() (FrameSource, error) {
+		opens++
+		if opens < 4 {
+			return nil, errors.ErrCameraNotFound
+		}
+		return &failingSource{}, nil
+	})
+	defer r.Close()
+
+	// Fatal errors disconnect right away
+	if _, err := r.ReadFrameWithContext(context.Background()); !errors.IsCameraLost(err) {
+		t.Fatalf("Expected camera lost error, got %v", err)
+	}
+
+	wantDelays := []time.Duration{2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond}
+	for i, want := range wantDelays {
+		_, err := r.ReadFrameWithContext(context.Background())
+		if !errors.IsCameraLost(err) || !errors.IsRetryable(err) {
+			t.Fatalf("Attempt %d: expected retryable camera lost error, got %v", i+1, err)
+		}
+		if r.delay != want {
+			t.Errorf("Attempt %d: expected backoff %v, got %v", i+1, want, r.delay)
+		}
+	}
+
+	if _, err := r.ReadFrameWithContext(context.Background()); err != nil {
+		t.Errorf("Expected frame after reconnecting, got %v", err)
+	}
+	if opens != 4 {
+		t.Errorf("Expected 4 attempts to open the camera, got %d", opens)
+	}
+}
+
+func TestReconnectingSource_Cancelled(t *testing.T) {
+	r := newTestReconnectingSource(&failingSource{err: errors.ErrCameraNotFound}, func() (FrameSource, error) {
+		t.Error("Camera must not be reopened after cancellation")
+		return nil, errors.ErrCameraNotFound
+	})
+	r.minDelay = time.Hour
+
+	if _, err := r.ReadFrameWithContext(context.Background()); !errors.IsCameraLost(err) {
+		t.Fatalf("Expected camera lost error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.ReadFrameWithContext(ctx); err == nil || errors.IsCameraLost(err) {
+		t.Errorf("Expected context error, got %v", err)
+	}
+
+	if w, h := r.Dimensions(); w != 4 || h != 4 {
+		t.Errorf("Expected last known dimensions while disconnected, got %dx%d", w, h)
+	}
+}
@@ -0,0 +1,81 @@
+package camera
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// ScaleMode selects how frames are fitted into the output size.
+type ScaleMode string
+
+// Scale modes.
+const (
+	// ScaleFit shows the whole frame with its aspect ratio intact, adding
+	// black bars where its shape differs from the output.
+	ScaleFit ScaleMode = "fit"
+	// ScaleFill covers the whole output with its aspect ratio intact,
+	// cropping the edges of the frame that do not fit.
+	ScaleFill ScaleMode = "fill"
+	// ScaleStretch stretches the frame to the output size, distorting it
+	// unless their shapes match.
+	ScaleStretch ScaleMode = "stretch"
+)
+
+// ScaleImage scales img to width x height output pixels according to mode.
+// pixelAspect is the height of an output pixel divided by its width, which is
+// about 2 for terminal cells and 1 when cells hold two pixels stacked on top
+// of each other. The result always has the requested size.
+func ScaleImage(img image.Image, width, height uint, mode ScaleMode, pixelAspect float64) image.Image {
+	b := img.Bounds()
+	if mode == ScaleStretch || pixelAspect <= 0 || b.Empty() || width == 0 || height == 0 {
+		return ResizeImage(img, width, height)
+	}
+
+	// The frame's aspect ratio measured in output pixels
+	aspect := float64(b.Dx()) / float64(b.Dy()) * pixelAspect
+	outAspect := float64(width) / float64(height)
+
+	if mode == ScaleFill {
+		return ResizeImage(cropToAspect(img, aspect/outAspect), width, height)
+	}
+
+	// Fit: scale to the largest size that fits, centered on black
+	w, h := width, height
+	if aspect > outAspect {
+		h = uint(max(math.Round(float64(width)/aspect), 1))
+	} else {
+		w = uint(max(math.Round(float64(height)*aspect), 1))
+	}
+	if w == width && h == height {
+		return ResizeImage(img, width, height)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	draw.Draw(out, out.Rect, image.Black, image.Point{}, draw.Src)
+
+	offset := image.Pt(int(width-w)/2, int(height-h)/2)
+	scaled := ResizeImage(img, w, h)
+	draw.Draw(out, image.Rectangle{Min: offset, Max: offset.Add(image.Pt(int(w), int(h)))}, scaled, scaled.Bounds().Min, draw.Src)
+	return out
+}
+
+// cropToAspect returns the center of img, narrowed by ratio if it is above 1
+// or shortened by 1/ratio if it is below 1.
+func cropToAspect(img image.Image, ratio float64) image.Image {
+	b := img.Bounds()
+	r := b
+	if ratio > 1 {
+		w := max(int(math.Round(float64(b.Dx())/ratio)), 1)
+		r.Min.X += (b.Dx() - w) / 2
+		r.Max.X = r.Min.X + w
+	} else {
+		h := max(int(math.Round(float64(b.Dy())*ratio)), 1)
+		r.Min.Y += (b.Dy() - h) / 2
+		r.Max.Y = r.Min.Y + h
+	}
+	if r == b {
+		return img
+	}
+	return cropImage(img, r)
+}
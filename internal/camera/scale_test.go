@@ -0,0 +1,121 @@
+package camera
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// solidImage returns a width x height image filled with c.
+func solidImage(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestScaleImage_Size(t *testing.T) {
+	img := solidImage(160, 90, color.RGBA{255, 255, 255, 255})
+
+	for _, mode := range []ScaleMode{ScaleFit, ScaleFill, ScaleStretch} {
+		for _, aspect := range []float64{0, 1, 2} {
+			b := ScaleImage(img, 40, 20, mode, aspect).Bounds()
+			if b.Dx() != 40 || b.Dy() != 20 {
+				t.Errorf("%s with pixel aspect %g: expected 40x20, got %dx%d", mode, aspect, b.Dx(), b.Dy())
+			}
+		}
+	}
+}
+
+func TestScaleImage_Fit(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	img := solidImage(160, 90, white)
+
+	// 16:9 shown on square pixels in a 40x40 output: 40x23 centered
+	out := ScaleImage(img, 40, 40, ScaleFit, 1)
+	at := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(out.At(x, y)).(color.RGBA)
+	}
+
+	if got := at(20, 0); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected black bar at the top, got %v", got)
+	}
+	if got := at(20, 39); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected black bar at the bottom, got %v", got)
+	}
+	if got := at(20, 20); got != white {
+		t.Errorf("Expected the frame in the middle, got %v", got)
+	}
+
+	// Cells twice as tall as wide: 16:9 becomes 40x11 in a 40x20 output
+	out = ScaleImage(img, 40, 20, ScaleFit, 2)
+	rows := 0
+	for y := 0; y < 20; y++ {
+		if c := color.RGBAModel.Convert(out.At(0, y)).(color.RGBA); c == white {
+			rows++
+		}
+	}
+	if rows != 11 {
+		t.Errorf("Expected 11 rows of frame, got %d", rows)
+	}
+}
+
+func TestScaleImage_FitPillarbox(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	img := solidImage(90, 160, white)
+
+	out := ScaleImage(img, 40, 20, ScaleFit, 2)
+	cols := 0
+	for x := 0; x < 40; x++ {
+		if c := color.RGBAModel.Convert(out.At(x, 10)).(color.RGBA); c == white {
+			cols++
+		}
+	}
+	// 9:16 at a pixel aspect of 2 is 20 rows of 22.5 pixels
+	if cols != 23 && cols != 22 {
+		t.Errorf("Expected about 22 columns of frame, got %d", cols)
+	}
+}
+
+func TestScaleImage_Fill(t *testing.T) {
+	// Left half red, right half blue; a square output on square pixels
+	// crops the sides of the 4:1 frame, leaving the middle
+	img := image.NewRGBA(image.Rect(0, 0, 80, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 80; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= 40 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	out := ScaleImage(img, 20, 20, ScaleFill, 1)
+	if c := color.RGBAModel.Convert(out.At(0, 10)).(color.RGBA); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected red on the left, got %v", c)
+	}
+	if c := color.RGBAModel.Convert(out.At(19, 10)).(color.RGBA); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("Expected blue on the right, got %v", c)
+	}
+}
+
+func TestCropToAspect(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 160, 90))
+
+	tests := []struct {
+		ratio float64
+		want  image.Rectangle
+	}{
+		{1, image.Rect(0, 0, 160, 90)},
+		{2, image.Rect(40, 0, 120, 90)},
+		{0.5, image.Rect(0, 22, 160, 67)},
+	}
+
+	for _, tt := range tests {
+		if got := cropToAspect(img, tt.ratio).Bounds(); got != tt.want {
+			t.Errorf("cropToAspect(%g) = %v, want %v", tt.ratio, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package camera provides functionality for webcam capture and image processing.
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"time"
+
+	"github.com/nfnt/resize"
+)
+
+// FrameSource is implemented by anything that can feed frames into the
+// render loop: webcams, video files, still images or synthetic generators.
+type FrameSource interface {
+	// ReadFrameWithContext returns the next frame from the source.
+	ReadFrameWithContext(ctx context.Context) (image.Image, error)
+	// Close releases any resources held by the source.
+	Close()
+	// Dimensions returns the width and height of the frames produced by the source.
+	Dimensions() (uint, uint)
+}
+
+// Pauser is implemented by sources that can be paused and resumed.
+type Pauser interface {
+	// TogglePause pauses or resumes playback and reports whether the source is now paused.
+	TogglePause() bool
+}
+
+// Seeker is implemented by sources that support seeking relative to the
+// current playback position.
+type Seeker interface {
+	Seek(offset time.Duration) error
+}
+
+// Downscaler is implemented by sources that can shrink frames before
+// converting them, which is much cheaper than converting full size frames
+// only to resize them afterwards.
+type Downscaler interface {
+	// SetMaxSize makes the source deliver frames no larger than needed to
+	// cover width x height, keeping their aspect ratio. Zero disables it.
+	// It is safe to call while frames are read on another goroutine.
+	SetMaxSize(width, height uint)
+}
+
+// FramePooler is implemented by sources that can take their frame buffers
+// from a FramePool.
+type FramePooler interface {
+	SetFramePool(pool *FramePool)
+}
+
+// coverSize returns the smallest size with the aspect ratio of width x height
+// that covers maxWidth x maxHeight. It reports false if the frame is already
+// that small or no maximum is set.
+func coverSize(width, height, maxWidth, maxHeight int) (int, int, bool) {
+	if maxWidth <= 0 || maxHeight <= 0 || width <= maxWidth || height <= maxHeight {
+		return width, height, false
+	}
+
+	scale := max(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	w := max(int(math.Ceil(float64(width)*scale)), maxWidth)
+	h := max(int(math.Ceil(float64(height)*scale)), maxHeight)
+	return w, h, true
+}
+
+// ResizeImage resizes an image to the specified dimensions. The result
+// always starts at the origin, even when img is a cropped region that already
+// has the requested size.
+func ResizeImage(img image.Image, width, height uint) image.Image {
+	resized := resize.Resize(width, height, img, resize.Bilinear)
+	if b := resized.Bounds(); b.Min != (image.Point{}) {
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Rect, resized, b.Min, draw.Src)
+		return dst
+	}
+	return resized
+}
+
+// cropImage returns the part of img inside r. The result shares its pixels
+// with img where the image type allows it.
+func cropImage(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Rect, img, r.Min, draw.Src)
+	return dst
+}
+
+// pacer spaces out frames to honor a source's native frame rate.
+type pacer struct {
+	delay time.Duration
+	next  time.Time
+}
+
+// newPacer returns a pacer for the given frame rate in frames per second.
+func newPacer(fps float64) pacer {
+	return pacer{delay: time.Duration(float64(time.Second) / fps)}
+}
+
+// wait blocks until the next frame is due or the context is cancelled.
+func (p *pacer) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	now := time.Now()
+	if p.next.IsZero() {
+		p.next = now
+	}
+
+	if d := p.next.Sub(now); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	p.next = p.next.Add(p.delay)
+	return nil
+}
+
+// behind returns how many frames the caller has fallen behind schedule and
+// moves the schedule forward accordingly, so those frames can be dropped.
+func (p *pacer) behind() int {
+	late := time.Since(p.next)
+	if p.next.IsZero() || late <= p.delay {
+		return 0
+	}
+
+	n := late / p.delay
+	p.next = p.next.Add(n * p.delay)
+	return int(n)
+}
+
+// reset restarts the schedule from the next frame, e.g. after pausing.
+func (p *pacer) reset() {
+	p.next = time.Time{}
+}
@@ -0,0 +1,95 @@
+package camera
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// fakeSource is a minimal FrameSource used to exercise code that only
+// depends on the interface.
+type fakeSource struct {
+	frame  image.Image
+	closed bool
+}
+
+func (f *fakeSource) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.frame, nil
+}
+
+func (f *fakeSource) Close() {
+	f.closed = true
+}
+
+func (f *fakeSource) Dimensions() (uint, uint) {
+	b := f.frame.Bounds()
+	return uint(b.Dx()), uint(b.Dy())
+}
+
+func TestFrameSource_Fake(t *testing.T) {
+	var src FrameSource = &fakeSource{frame: image.NewRGBA(image.Rect(0, 0, 64, 48))}
+
+	img, err := src.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	resized := ResizeImage(img, 32, 24)
+	if b := resized.Bounds(); b.Dx() != 32 || b.Dy() != 24 {
+		t.Errorf("Expected 32x24 resized image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	w, h := src.Dimensions()
+	if w != 64 || h != 48 {
+		t.Errorf("Expected dimensions 64x48, got %dx%d", w, h)
+	}
+
+	src.Close()
+	if !src.(*fakeSource).closed {
+		t.Error("Expected Close to be called")
+	}
+}
+
+func TestCoverSize(t *testing.T) {
+	tests := []struct {
+		name                string
+		width, height       int
+		maxWidth, maxHeight int
+		wantW, wantH        int
+		wantOK              bool
+	}{
+		{"no maximum", 1920, 1080, 0, 0, 1920, 1080, false},
+		{"already small", 320, 240, 640, 480, 320, 240, false},
+		{"one side small", 1920, 400, 640, 480, 1920, 400, false},
+		{"same aspect", 1920, 1080, 160, 90, 160, 90, true},
+		{"wider target", 1920, 1080, 200, 50, 200, 113, true},
+		{"taller target", 1920, 1080, 100, 100, 178, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, ok := coverSize(tt.width, tt.height, tt.maxWidth, tt.maxHeight)
+			if w != tt.wantW || h != tt.wantH || ok != tt.wantOK {
+				t.Errorf("coverSize() = %d, %d, %v, want %d, %d, %v", w, h, ok, tt.wantW, tt.wantH, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResizeImage_Region(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	img.Set(4, 4, color.White)
+
+	// A region that already has the requested size still starts at the origin
+	got := ResizeImage(img.SubImage(image.Rect(4, 4, 8, 8)), 4, 4)
+	if b := got.Bounds(); b != image.Rect(0, 0, 4, 4) {
+		t.Fatalf("Expected bounds at the origin, got %v", b)
+	}
+	if r, _, _, _ := got.At(0, 0).RGBA(); r != 0xffff {
+		t.Error("Expected the region's first pixel at the origin")
+	}
+}
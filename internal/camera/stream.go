@@ -0,0 +1,313 @@
+package camera
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// y4mMagic starts every YUV4MPEG2 stream.
+const y4mMagic = "YUV4MPEG2"
+
+// y4mHeader holds the stream parameters of a YUV4MPEG2 header.
+type y4mHeader struct {
+	width      int
+	height     int
+	fps        float64
+	subsample  image.YCbCrSubsampleRatio
+	mono       bool
+	alpha      bool // 444alpha streams carry a fourth plane we skip
+	fullRange  bool
+	colorspace string
+}
+
+// RawStream reads uncompressed frames from a pipe, either as a YUV4MPEG2
+// stream (as written by `ffmpeg -f yuv4mpegpipe`) or as headerless rgb24
+// rawvideo of a known size. It does not depend on OpenCV.
+type RawStream struct {
+	r      *bufio.Reader
+	closer io.Closer
+	name   string
+	y4m    *y4mHeader
+	width  uint
+	height uint
+	pace   pacer
+	paced  bool
+	buf    []byte
+	frame  *image.YCbCr
+}
+
+var _ FrameSource = (*RawStream)(nil)
+
+// NewRawStream starts reading frames from r. If the stream begins with a
+// YUV4MPEG2 header, size, frame rate and colorspace are taken from it and
+// frames are paced to the stream's frame rate. Otherwise the stream is read
+// as rgb24 frames of width x height, as fast as they arrive.
+func NewRawStream(r io.Reader, name string, width, height uint) (*RawStream, error) {
+	s := &RawStream{
+		r:    bufio.NewReaderSize(r, 1<<20),
+		name: name,
+	}
+	if c, ok := r.(io.Closer); ok {
+		s.closer = c
+	}
+
+	magic, err := s.r.Peek(len(y4mMagic))
+	if err == nil && string(magic) == y4mMagic {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return nil, errors.NewFileError(name, "read", fmt.Errorf("%w: %v", errors.ErrFileReadFailed, err))
+		}
+
+		h, err := parseY4MHeader(line)
+		if err != nil {
+			return nil, errors.NewFileError(name, "decode", err)
+		}
+
+		s.y4m = h
+		s.width, s.height = uint(h.width), uint(h.height)
+		s.frame = image.NewYCbCr(image.Rect(0, 0, h.width, h.height), h.subsample)
+		if h.fps > 0 {
+			s.pace = newPacer(h.fps)
+			s.paced = true
+		}
+		return s, nil
+	}
+
+	if width == 0 || height == 0 {
+		return nil, errors.NewImageError("rawvideo", fmt.Sprintf("%dx%d", width, height), errors.ErrInvalidDimensions)
+	}
+
+	s.width, s.height = width, height
+	s.buf = make([]byte, width*height*3)
+	return s, nil
+}
+
+// Close closes the underlying reader, if it can be closed.
+func (s *RawStream) Close() {
+	if s.closer != nil {
+		_ = s.closer.Close()
+	}
+}
+
+// Dimensions returns the width and height of the stream's frames.
+func (s *RawStream) Dimensions() (uint, uint) {
+	return s.width, s.height
+}
+
+// ReadFrameWithContext reads the next frame from the stream. When the stream
+// ends, an error wrapping errors.ErrEndOfStream is returned.
+func (s *RawStream) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	if !s.paced {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("context cancelled: %w", err)
+		}
+	} else {
+		if err := s.pace.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Drop frames we are too late for to keep playback in real time
+		for n := s.pace.behind(); n > 0; n-- {
+			if err := s.readY4MFrame(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if s.y4m == nil {
+		return s.readRGB24()
+	}
+
+	if err := s.readY4MFrame(); err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(s.frame.Rect)
+	if s.y4m.mono {
+		// Chroma planes are neutral, only luma carries information
+		for i, y := range s.frame.Y {
+			img.Pix[i*4+0] = y
+			img.Pix[i*4+1] = y
+			img.Pix[i*4+2] = y
+			img.Pix[i*4+3] = 255
+		}
+	} else {
+		draw.Draw(img, img.Rect, s.frame, image.Point{}, draw.Src)
+	}
+
+	return img, nil
+}
+
+// readRGB24 reads a single headerless rgb24 frame.
+func (s *RawStream) readRGB24() (image.Image, error) {
+	if _, err := io.ReadFull(s.r, s.buf); err != nil {
+		return nil, s.readError(err)
+	}
+
+	w, h := int(s.width), int(s.height)
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < w*h; i++ {
+		img.Pix[i*4+0] = s.buf[i*3+0]
+		img.Pix[i*4+1] = s.buf[i*3+1]
+		img.Pix[i*4+2] = s.buf[i*3+2]
+		img.Pix[i*4+3] = 255
+	}
+
+	return img, nil
+}
+
+// readY4MFrame reads the next FRAME of a YUV4MPEG2 stream into s.frame.
+func (s *RawStream) readY4MFrame() error {
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		return s.readError(err)
+	}
+	if !strings.HasPrefix(line, "FRAME") {
+		return errors.NewFileError(s.name, "decode", fmt.Errorf("%w: expected FRAME, got %q", errors.ErrImageDecodeFailed, strings.TrimSpace(line)))
+	}
+
+	if _, err := io.ReadFull(s.r, s.frame.Y); err != nil {
+		return s.readError(err)
+	}
+
+	if s.y4m.mono {
+		if !s.y4m.fullRange {
+			for i, v := range s.frame.Y {
+				s.frame.Y[i] = lumaRange[v]
+			}
+		}
+		return nil
+	}
+
+	if _, err := io.ReadFull(s.r, s.frame.Cb); err != nil {
+		return s.readError(err)
+	}
+	if _, err := io.ReadFull(s.r, s.frame.Cr); err != nil {
+		return s.readError(err)
+	}
+	if s.y4m.alpha {
+		if _, err := s.r.Discard(len(s.frame.Y)); err != nil {
+			return s.readError(err)
+		}
+	}
+
+	if !s.y4m.fullRange {
+		expandVideoRange(s.frame)
+	}
+
+	return nil
+}
+
+// readError maps a read failure to end of stream or a decode error.
+func (s *RawStream) readError(err error) error {
+	if err == io.EOF {
+		return errors.NewFileError(s.name, "read", errors.ErrEndOfStream)
+	}
+	if err == io.ErrUnexpectedEOF {
+		return errors.NewFileError(s.name, "read", fmt.Errorf("%w: truncated frame", errors.ErrEndOfStream))
+	}
+	return errors.NewFileError(s.name, "read", fmt.Errorf("%w: %v", errors.ErrFileReadFailed, err))
+}
+
+// parseY4MHeader parses a YUV4MPEG2 stream header line.
+func parseY4MHeader(line string) (*y4mHeader, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != y4mMagic {
+		return nil, fmt.Errorf("%w: not a YUV4MPEG2 stream", errors.ErrImageDecodeFailed)
+	}
+
+	h := &y4mHeader{
+		subsample:  image.YCbCrSubsampleRatio420,
+		colorspace: "420jpeg",
+	}
+
+	for _, f := range fields[1:] {
+		key, value := f[0], f[1:]
+		switch key {
+		case 'W':
+			h.width, _ = strconv.Atoi(value)
+		case 'H':
+			h.height, _ = strconv.Atoi(value)
+		case 'F':
+			num, den, ok := strings.Cut(value, ":")
+			n, err1 := strconv.ParseFloat(num, 64)
+			d, err2 := strconv.ParseFloat(den, 64)
+			if ok && err1 == nil && err2 == nil && n > 0 && d > 0 {
+				h.fps = n / d
+			}
+		case 'C':
+			h.colorspace = value
+		case 'X':
+			if strings.EqualFold(value, "COLORRANGE=FULL") {
+				h.fullRange = true
+			}
+		}
+	}
+
+	if h.width <= 0 || h.height <= 0 {
+		return nil, fmt.Errorf("%w: %dx%d", errors.ErrInvalidDimensions, h.width, h.height)
+	}
+
+	switch h.colorspace {
+	case "420jpeg", "420paldv", "420mpeg2", "420":
+		h.subsample = image.YCbCrSubsampleRatio420
+	case "422":
+		h.subsample = image.YCbCrSubsampleRatio422
+	case "444":
+		h.subsample = image.YCbCrSubsampleRatio444
+	case "444alpha":
+		h.subsample = image.YCbCrSubsampleRatio444
+		h.alpha = true
+	case "411":
+		h.subsample = image.YCbCrSubsampleRatio411
+	case "mono":
+		h.mono = true
+	default:
+		return nil, fmt.Errorf("%w: colorspace %q", errors.ErrImageDecodeFailed, h.colorspace)
+	}
+
+	return h, nil
+}
+
+// Lookup tables expanding limited (video) range samples to full range.
+var lumaRange, chromaRange = videoRangeTables()
+
+func videoRangeTables() (luma, chroma [256]byte) {
+	clamp := func(v int) byte {
+		if v < 0 {
+			return 0
+		}
+		if v > 255 {
+			return 255
+		}
+		return byte(v)
+	}
+
+	for i := 0; i < 256; i++ {
+		luma[i] = clamp(((i-16)*255 + 109) / 219)
+		chroma[i] = clamp(128 + ((i-128)*255)/224)
+	}
+	return luma, chroma
+}
+
+// expandVideoRange converts a limited range (16-235/240) frame in place to the
+// full range Go's image.YCbCr expects.
+func expandVideoRange(img *image.YCbCr) {
+	for i, v := range img.Y {
+		img.Y[i] = lumaRange[v]
+	}
+	for i, v := range img.Cb {
+		img.Cb[i] = chromaRange[v]
+	}
+	for i, v := range img.Cr {
+		img.Cr[i] = chromaRange[v]
+	}
+}
@@ -0,0 +1,213 @@
+package camera
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"image"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// y4mStream builds a YUV4MPEG2 stream with the given header and frames
+// filled with constant Y, Cb and Cr values.
+func y4mStream(header string, frames int, ySize, cSize int, y, cb, cr byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(header + "\n")
+	for i := 0; i < frames; i++ {
+		b.WriteString("FRAME\n")
+		b.Write(bytes.Repeat([]byte{y}, ySize))
+		b.Write(bytes.Repeat([]byte{cb}, cSize))
+		b.Write(bytes.Repeat([]byte{cr}, cSize))
+	}
+	return b.Bytes()
+}
+
+func TestParseY4MHeader(t *testing.T) {
+	h, err := parseY4MHeader("YUV4MPEG2 W640 H480 F30000:1001 Ip A1:1 C422 XCOLORRANGE=FULL\n")
+	if err != nil {
+		t.Fatalf("parseY4MHeader returned error: %v", err)
+	}
+
+	if h.width != 640 || h.height != 480 {
+		t.Errorf("Expected 640x480, got %dx%d", h.width, h.height)
+	}
+
+	if h.fps < 29.96 || h.fps > 29.98 {
+		t.Errorf("Expected ~29.97 fps, got %f", h.fps)
+	}
+
+	if h.subsample != image.YCbCrSubsampleRatio422 {
+		t.Errorf("Expected 4:2:2 subsampling, got %v", h.subsample)
+	}
+
+	if !h.fullRange {
+		t.Error("Expected full color range")
+	}
+}
+
+func TestParseY4MHeader_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"missing magic", "W640 H480"},
+		{"missing size", "YUV4MPEG2 F25:1"},
+		{"high bit depth", "YUV4MPEG2 W2 H2 C420p10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseY4MHeader(tt.header); err == nil {
+				t.Errorf("Expected error for header %q", tt.header)
+			}
+		})
+	}
+
+	// An unsupported colorspace is a problem of the stream, not the camera
+	_, err := parseY4MHeader("YUV4MPEG2 W2 H2 C420p10")
+	if !stderrors.Is(err, errors.ErrImageDecodeFailed) || stderrors.Is(err, errors.ErrCameraUnsupported) {
+		t.Errorf("Expected ErrImageDecodeFailed, got %v", err)
+	}
+}
+
+func TestRawStream_Y4M(t *testing.T) {
+	// 4x2 4:2:0 frames, neutral chroma, full range white
+	data := y4mStream("YUV4MPEG2 W4 H2 F50:1 C420jpeg XCOLORRANGE=FULL", 2, 8, 2, 255, 128, 128)
+
+	s, err := NewRawStream(bytes.NewReader(data), "stdin", 0, 0)
+	if err != nil {
+		t.Fatalf("NewRawStream returned error: %v", err)
+	}
+	defer s.Close()
+
+	if w, h := s.Dimensions(); w != 4 || h != 2 {
+		t.Errorf("Expected 4x2, got %dx%d", w, h)
+	}
+
+	for i := 0; i < 2; i++ {
+		img, err := s.ReadFrameWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("Frame %d: ReadFrameWithContext returned error: %v", i, err)
+		}
+
+		r, g, b, _ := img.At(3, 1).RGBA()
+		if r>>8 != 255 || g>>8 != 255 || b>>8 != 255 {
+			t.Errorf("Frame %d: expected white pixel, got %d,%d,%d", i, r>>8, g>>8, b>>8)
+		}
+	}
+
+	if _, err := s.ReadFrameWithContext(context.Background()); !errors.IsEndOfStream(err) {
+		t.Errorf("Expected end of stream, got %v", err)
+	}
+}
+
+func TestRawStream_Y4MLimitedRange(t *testing.T) {
+	// Limited range black (Y=16) must map to full range black
+	data := y4mStream("YUV4MPEG2 W2 H2 F1000:1 C444", 1, 4, 4, 16, 128, 128)
+
+	s, err := NewRawStream(bytes.NewReader(data), "stdin", 0, 0)
+	if err != nil {
+		t.Fatalf("NewRawStream returned error: %v", err)
+	}
+
+	img, err := s.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	r, g, b, _ := img.At(0, 0).RGBA()
+	if r != 0 || g != 0 || b != 0 {
+		t.Errorf("Expected black pixel, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestRawStream_Y4MMonoUnpaced(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("YUV4MPEG2 W2 H1 Cmono XCOLORRANGE=FULL\n")
+	b.WriteString("FRAME\n")
+	b.Write([]byte{0, 200})
+
+	s, err := NewRawStream(&b, "stdin", 0, 0)
+	if err != nil {
+		t.Fatalf("NewRawStream returned error: %v", err)
+	}
+
+	img, err := s.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	r, g, b2, _ := img.At(1, 0).RGBA()
+	if r>>8 != 200 || g>>8 != 200 || b2>>8 != 200 {
+		t.Errorf("Expected gray 200 pixel, got %d,%d,%d", r>>8, g>>8, b2>>8)
+	}
+}
+
+func TestRawStream_Paced(t *testing.T) {
+	data := y4mStream("YUV4MPEG2 W2 H2 F20:1 C444", 3, 4, 4, 128, 128, 128)
+
+	s, err := NewRawStream(bytes.NewReader(data), "stdin", 0, 0)
+	if err != nil {
+		t.Fatalf("NewRawStream returned error: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := s.ReadFrameWithContext(context.Background()); err != nil {
+			t.Fatalf("ReadFrameWithContext returned error: %v", err)
+		}
+	}
+
+	// Three frames at 20 fps take at least two frame intervals
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected frames to be paced to 20 fps, took %v", elapsed)
+	}
+}
+
+func TestRawStream_RGB24(t *testing.T) {
+	data := []byte{
+		255, 0, 0, 0, 255, 0,
+		0, 0, 255, 255, 255, 255,
+	}
+
+	s, err := NewRawStream(bytes.NewReader(data), "stdin", 2, 2)
+	if err != nil {
+		t.Fatalf("NewRawStream returned error: %v", err)
+	}
+
+	img, err := s.ReadFrameWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("ReadFrameWithContext returned error: %v", err)
+	}
+
+	r, g, b, _ := img.At(1, 0).RGBA()
+	if r != 0 || g>>8 != 255 || b != 0 {
+		t.Errorf("Expected green pixel, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+
+	if _, err := s.ReadFrameWithContext(context.Background()); !errors.IsEndOfStream(err) {
+		t.Errorf("Expected end of stream, got %v", err)
+	}
+}
+
+func TestRawStream_RGB24Truncated(t *testing.T) {
+	s, err := NewRawStream(io.LimitReader(strings.NewReader(strings.Repeat("x", 20)), 5), "stdin", 2, 2)
+	if err != nil {
+		t.Fatalf("NewRawStream returned error: %v", err)
+	}
+
+	if _, err := s.ReadFrameWithContext(context.Background()); !errors.IsEndOfStream(err) {
+		t.Errorf("Expected end of stream for truncated frame, got %v", err)
+	}
+}
+
+func TestRawStream_RGB24NeedsSize(t *testing.T) {
+	if _, err := NewRawStream(bytes.NewReader(nil), "stdin", 0, 0); err == nil {
+		t.Error("Expected error for rawvideo without dimensions, got none")
+	}
+}
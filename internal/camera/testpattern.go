@@ -0,0 +1,204 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// patternFuncs renders frame n of each named test pattern into img.
+var patternFuncs = map[string]func(img *image.RGBA, n int){
+	"bars":         drawBars,
+	"gradient":     drawGradient,
+	"checkerboard": drawCheckerboard,
+	"noise":        drawNoise,
+	"bounce":       drawBounce,
+}
+
+// barColors are the classic 75% color bars, left to right.
+var barColors = []color.RGBA{
+	{191, 191, 191, 255}, // white
+	{191, 191, 0, 255},   // yellow
+	{0, 191, 191, 255},   // cyan
+	{0, 191, 0, 255},     // green
+	{191, 0, 191, 255},   // magenta
+	{191, 0, 0, 255},     // red
+	{0, 0, 191, 255},     // blue
+	{0, 0, 0, 255},       // black
+}
+
+// TestPatterns returns the names of the available test patterns.
+func TestPatterns() []string {
+	names := make([]string, 0, len(patternFuncs))
+	for name := range patternFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestPattern is a synthetic frame source that needs no camera. Frames are
+// a pure function of the frame number, so output is deterministic and can be
+// compared against golden files.
+type TestPattern struct {
+	draw   func(img *image.RGBA, n int)
+	width  uint
+	height uint
+	frame  int
+	pace   pacer
+	paced  bool
+}
+
+var _ FrameSource = (*TestPattern)(nil)
+
+// NewTestPattern creates a generator for the named pattern. Frames are paced
+// to fps; an fps of zero generates frames as fast as they are read.
+func NewTestPattern(name string, width, height uint, fps float64) (*TestPattern, error) {
+	draw, ok := patternFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown test pattern %q (available: %v)", errors.ErrInvalidConfig, name, TestPatterns())
+	}
+	if width == 0 || height == 0 {
+		return nil, errors.NewImageError("testpattern", fmt.Sprintf("%dx%d", width, height), errors.ErrInvalidDimensions)
+	}
+
+	t := &TestPattern{
+		draw:   draw,
+		width:  width,
+		height: height,
+	}
+	if fps > 0 {
+		t.pace = newPacer(fps)
+		t.paced = true
+	}
+
+	return t, nil
+}
+
+// Close is a no-op; test patterns hold no resources.
+func (t *TestPattern) Close() {}
+
+// Dimensions returns the width and height of the generated frames.
+func (t *TestPattern) Dimensions() (uint, uint) {
+	return t.width, t.height
+}
+
+// Frame renders frame n of the pattern.
+func (t *TestPattern) Frame(n int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, int(t.width), int(t.height)))
+	t.draw(img, n)
+	return img
+}
+
+// ReadFrameWithContext returns the next frame of the pattern.
+func (t *TestPattern) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	if t.paced {
+		if err := t.pace.wait(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	img := t.Frame(t.frame)
+	t.frame++
+	return img, nil
+}
+
+// fill sets the pixels of img inside r to c.
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawBars draws static vertical color bars.
+func drawBars(img *image.RGBA, _ int) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for i, c := range barColors {
+		fill(img, image.Rect(i*w/len(barColors), 0, (i+1)*w/len(barColors), h), c)
+	}
+}
+
+// drawGradient draws a gray ramp in the top half and a hue sweep in the
+// bottom half. The hue sweep scrolls slowly with the frame number.
+func drawGradient(img *image.RGBA, n int) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for x := 0; x < w; x++ {
+		v := uint8(x * 255 / max(w-1, 1))
+		gray := color.RGBA{v, v, v, 255}
+
+		hue := float64((x*360/max(w, 1) + n) % 360)
+		r, g, b := colorful.Hsv(hue, 1, 1).RGB255()
+		hueColor := color.RGBA{r, g, b, 255}
+
+		for y := 0; y < h; y++ {
+			if y < h/2 {
+				img.SetRGBA(x, y, gray)
+			} else {
+				img.SetRGBA(x, y, hueColor)
+			}
+		}
+	}
+}
+
+// drawCheckerboard draws a black and white checkerboard moving diagonally.
+func drawCheckerboard(img *image.RGBA, n int) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	size := max(max(w, h)/16, 1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if ((x+n)/size+(y+n)/size)%2 == 0 {
+				img.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0, 255})
+			}
+		}
+	}
+}
+
+// drawNoise draws gray noise seeded by the frame number.
+func drawNoise(img *image.RGBA, n int) {
+	rng := rand.New(rand.NewSource(int64(n)))
+	for i := 0; i < len(img.Pix); i += 4 {
+		v := uint8(rng.Intn(256))
+		img.Pix[i+0] = v
+		img.Pix[i+1] = v
+		img.Pix[i+2] = v
+		img.Pix[i+3] = 255
+	}
+}
+
+// drawBounce draws a white box bouncing around a black background.
+func drawBounce(img *image.RGBA, n int) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	fill(img, img.Rect, color.RGBA{0, 0, 0, 255})
+
+	size := max(min(w, h)/8, 1)
+	speed := max(min(w, h)/60, 1)
+	x := bounce(n*speed, w-size)
+	y := bounce(n*speed*2/3, h-size)
+	fill(img, image.Rect(x, y, x+size, y+size), color.RGBA{255, 255, 255, 255})
+}
+
+// bounce maps a position moving forward forever onto [0, limit], reflecting
+// off both ends.
+func bounce(pos, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	pos %= 2 * limit
+	if pos > limit {
+		return 2*limit - pos
+	}
+	return pos
+}
@@ -0,0 +1,98 @@
+package camera
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"image/color"
+	"testing"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+func TestNewTestPattern_Unknown(t *testing.T) {
+	_, err := NewTestPattern("plaid", 64, 48, 0)
+	if !stderrors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for unknown pattern, got %v", err)
+	}
+}
+
+func TestNewTestPattern_InvalidDimensions(t *testing.T) {
+	if _, err := NewTestPattern("bars", 0, 48, 0); err == nil {
+		t.Error("Expected error for zero width, got none")
+	}
+}
+
+func TestTestPatterns_Deterministic(t *testing.T) {
+	for _, name := range TestPatterns() {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewTestPattern(name, 64, 48, 0)
+			if err != nil {
+				t.Fatalf("NewTestPattern returned error: %v", err)
+			}
+			defer p.Close()
+
+			img, err := p.ReadFrameWithContext(context.Background())
+			if err != nil {
+				t.Fatalf("ReadFrameWithContext returned error: %v", err)
+			}
+
+			if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 48 {
+				t.Errorf("Expected 64x48 frame, got %dx%d", b.Dx(), b.Dy())
+			}
+
+			if !bytes.Equal(p.Frame(7).Pix, p.Frame(7).Pix) {
+				t.Error("Expected identical output for the same frame number")
+			}
+		})
+	}
+}
+
+func TestTestPattern_Bars(t *testing.T) {
+	p, err := NewTestPattern("bars", 80, 10, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	img := p.Frame(0)
+	for i, expected := range barColors {
+		if got := img.RGBAAt(i*10+5, 5); got != expected {
+			t.Errorf("Bar %d: expected %v, got %v", i, expected, got)
+		}
+	}
+}
+
+func TestTestPattern_BounceMoves(t *testing.T) {
+	p, err := NewTestPattern("bounce", 120, 120, 0)
+	if err != nil {
+		t.Fatalf("NewTestPattern returned error: %v", err)
+	}
+
+	white := color.RGBA{255, 255, 255, 255}
+	if p.Frame(0).RGBAAt(0, 0) != white {
+		t.Error("Expected box in the top-left corner on the first frame")
+	}
+	if p.Frame(10).RGBAAt(0, 0) == white {
+		t.Error("Expected box to have moved away from the corner")
+	}
+}
+
+func TestBounce(t *testing.T) {
+	tests := []struct {
+		pos, limit, expected int
+	}{
+		{0, 10, 0},
+		{5, 10, 5},
+		{10, 10, 10},
+		{15, 10, 5},
+		{20, 10, 0},
+		{25, 10, 5},
+		{7, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := bounce(tt.pos, tt.limit); got != tt.expected {
+			t.Errorf("bounce(%d, %d) = %d, expected %d", tt.pos, tt.limit, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package camera
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Orientation mirrors, flips and rotates frames, for example to get a
+// self-view or to straighten a camera mounted sideways. Mirroring and flipping
+// are applied first, then the frame is rotated clockwise.
+type Orientation struct {
+	Mirror bool // swap left and right
+	Flip   bool // swap top and bottom
+	Rotate int  // clockwise rotation in degrees: 0, 90, 180 or 270
+}
+
+// IsIdentity reports whether the orientation leaves frames unchanged.
+func (o Orientation) IsIdentity() bool {
+	return !o.Mirror && !o.Flip && o.Rotate%360 == 0
+}
+
+// swapsAxes reports whether the orientation turns frames on their side.
+func (o Orientation) swapsAxes() bool {
+	return o.Rotate%180 != 0
+}
+
+// Size returns the size of a width x height frame after applying the
+// orientation. Rotating by 90 or 270 degrees swaps width and height; as that
+// is its own inverse, Size also maps a wanted output size back to the frame
+// size needed to produce it.
+func (o Orientation) Size(width, height uint) (uint, uint) {
+	if o.swapsAxes() {
+		return height, width
+	}
+	return width, height
+}
+
+// Apply returns img with the orientation applied, taking the new frame from
+// pool. img itself is returned when the orientation changes nothing.
+func (o Orientation) Apply(img image.Image, pool *FramePool) image.Image {
+	if o.IsIdentity() {
+		return img
+	}
+
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(img.Bounds())
+		draw.Draw(src, src.Rect, img, img.Bounds().Min, draw.Src)
+	}
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if o.swapsAxes() {
+		dw, dh = h, w
+	}
+	dst := pool.Get(image.Rect(0, 0, dw, dh))
+
+	rotate := (o.Rotate%360 + 360) % 360
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		sy := y
+		if o.Flip {
+			sy = h - 1 - y
+		}
+
+		for x := 0; x < w; x++ {
+			sx := x
+			if o.Mirror {
+				sx = w - 1 - x
+			}
+
+			// Where the mirrored and flipped pixel ends up after rotating
+			var dx, dy int
+			switch rotate {
+			case 90:
+				dx, dy = h-1-sy, sx
+			case 180:
+				dx, dy = w-1-sx, h-1-sy
+			case 270:
+				dx, dy = sy, w-1-sx
+			default:
+				dx, dy = sx, sy
+			}
+
+			i := dy*dst.Stride + dx*4
+			copy(dst.Pix[i:i+4], row[x*4:x*4+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,108 @@
+package camera
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// numberedImage returns a width x height image whose pixels hold their
+// 1-based position in row-major order in the red channel.
+func numberedImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(y*width + x + 1), A: 255})
+		}
+	}
+	return img
+}
+
+// pixelRows returns the red channel of img row by row.
+func pixelRows(img image.Image) [][]uint8 {
+	b := img.Bounds()
+	rows := make([][]uint8, b.Dy())
+	for y := range rows {
+		for x := 0; x < b.Dx(); x++ {
+			r, _, _, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			rows[y] = append(rows[y], uint8(r>>8))
+		}
+	}
+	return rows
+}
+
+func TestOrientation_Apply(t *testing.T) {
+	// The input is
+	//   1 2 3
+	//   4 5 6
+	tests := []struct {
+		name   string
+		orient Orientation
+		want   [][]uint8
+	}{
+		{"mirror", Orientation{Mirror: true}, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{"flip", Orientation{Flip: true}, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{"rotate 90", Orientation{Rotate: 90}, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{"rotate 180", Orientation{Rotate: 180}, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{"rotate 270", Orientation{Rotate: 270}, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+		{"mirror and rotate 90", Orientation{Mirror: true, Rotate: 90}, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{"mirror and flip", Orientation{Mirror: true, Flip: true}, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pixelRows(tt.orient.Apply(numberedImage(3, 2), nil))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for y := range got {
+				if string(got[y]) != string(tt.want[y]) {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOrientation_ApplyIdentity(t *testing.T) {
+	img := numberedImage(3, 2)
+	if got := (Orientation{}).Apply(img, nil); got != img {
+		t.Error("Expected identity orientation to return the frame itself")
+	}
+	if got := (Orientation{Rotate: 360}).Apply(img, nil); got != img {
+		t.Error("Expected full turn to return the frame itself")
+	}
+}
+
+func TestOrientation_ApplyNonRGBA(t *testing.T) {
+	gray := image.NewGray(image.Rect(10, 10, 14, 12))
+	gray.SetGray(10, 10, color.Gray{Y: 200})
+
+	got := Orientation{Rotate: 90}.Apply(gray, nil)
+	if b := got.Bounds(); b.Dx() != 2 || b.Dy() != 4 {
+		t.Fatalf("Expected 2x4 frame, got %dx%d", b.Dx(), b.Dy())
+	}
+	// The top-left pixel moves to the top-right corner
+	if r, _, _, _ := got.At(1, 0).RGBA(); r>>8 != 200 {
+		t.Errorf("Expected rotated pixel value 200, got %d", r>>8)
+	}
+}
+
+func TestOrientation_Size(t *testing.T) {
+	tests := []struct {
+		rotate       int
+		wantW, wantH uint
+	}{
+		{0, 160, 90},
+		{90, 90, 160},
+		{180, 160, 90},
+		{270, 90, 160},
+	}
+
+	for _, tt := range tests {
+		w, h := Orientation{Rotate: tt.rotate}.Size(160, 90)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("Rotate %d: expected %dx%d, got %dx%d", tt.rotate, tt.wantW, tt.wantH, w, h)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package camera
+
+import (
+	"bytes"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Pixel formats, as V4L2 fourcc codes.
+const (
+	pixFmtYUYV  uint32 = 'Y' | 'U'<<8 | 'Y'<<16 | 'V'<<24
+	pixFmtMJPEG uint32 = 'M' | 'J'<<8 | 'P'<<16 | 'G'<<24
+)
+
+// Constants from linux/videodev2.h.
+const (
+	v4l2BufTypeVideoCapture = 1
+	v4l2MemoryMmap          = 1
+	v4l2FieldAny            = 0
+
+	v4l2FrmSizeTypeDiscrete = 1
+	v4l2FrmIvalTypeDiscrete = 1
+
+	v4l2CapTimePerFrame = 0x1000
+
+	v4l2CapVideoCapture = 0x00000001
+	v4l2CapStreaming    = 0x04000000
+	v4l2CapDeviceCaps   = 0x80000000
+)
+
+// v4l2Capability mirrors struct v4l2_capability.
+type v4l2Capability struct {
+	Driver       [16]byte
+	Card         [32]byte
+	BusInfo      [32]byte
+	Version      uint32
+	Capabilities uint32
+	DeviceCaps   uint32
+	Reserved     [3]uint32
+}
+
+// v4l2PixFormat mirrors struct v4l2_pix_format.
+type v4l2PixFormat struct {
+	Width        uint32
+	Height       uint32
+	PixelFormat  uint32
+	Field        uint32
+	BytesPerLine uint32
+	SizeImage    uint32
+	Colorspace   uint32
+	Priv         uint32
+	Flags        uint32
+	YCbCrEnc     uint32
+	Quantization uint32
+	XferFunc     uint32
+}
+
+// v4l2Format mirrors struct v4l2_format. The format union contains pointers,
+// so it is pointer aligned.
+type v4l2Format struct {
+	Type uint32
+	_    [unsafe.Sizeof(uintptr(0)) - 4]byte
+	Fmt  [200]byte
+}
+
+// pix returns the single-planar pixel format stored in the format union.
+func (f *v4l2Format) pix() *v4l2PixFormat {
+	return (*v4l2PixFormat)(unsafe.Pointer(&f.Fmt[0]))
+}
+
+// v4l2FmtDesc mirrors struct v4l2_fmtdesc.
+type v4l2FmtDesc struct {
+	Index       uint32
+	Type        uint32
+	Flags       uint32
+	Description [32]byte
+	PixelFormat uint32
+	MbusCode    uint32
+	Reserved    [3]uint32
+}
+
+// v4l2FrmSizeEnum mirrors struct v4l2_frmsizeenum. Size holds either the
+// discrete width and height or the stepwise min/max/step width and height.
+type v4l2FrmSizeEnum struct {
+	Index       uint32
+	PixelFormat uint32
+	Type        uint32
+	Size        [6]uint32
+	Reserved    [2]uint32
+}
+
+// v4l2FrmIvalEnum mirrors struct v4l2_frmivalenum. Interval holds either the
+// discrete frame interval as numerator and denominator, or the stepwise
+// min/max/step intervals.
+type v4l2FrmIvalEnum struct {
+	Index       uint32
+	PixelFormat uint32
+	Width       uint32
+	Height      uint32
+	Type        uint32
+	Interval    [6]uint32
+	Reserved    [2]uint32
+}
+
+// v4l2Control mirrors struct v4l2_control.
+type v4l2Control struct {
+	ID    uint32
+	Value int32
+}
+
+// v4l2CaptureParm mirrors struct v4l2_captureparm.
+type v4l2CaptureParm struct {
+	Capability   uint32
+	CaptureMode  uint32
+	TimePerFrame struct {
+		Numerator   uint32
+		Denominator uint32
+	}
+	ExtendedMode uint32
+	ReadBuffers  uint32
+	Reserved     [4]uint32
+}
+
+// v4l2StreamParm mirrors struct v4l2_streamparm.
+type v4l2StreamParm struct {
+	Type uint32
+	Parm [200]byte
+}
+
+// capture returns the capture parameters stored in the parameter union.
+func (p *v4l2StreamParm) capture() *v4l2CaptureParm {
+	return (*v4l2CaptureParm)(unsafe.Pointer(&p.Parm[0]))
+}
+
+// v4l2RequestBuffers mirrors struct v4l2_requestbuffers.
+type v4l2RequestBuffers struct {
+	Count        uint32
+	Type         uint32
+	Memory       uint32
+	Capabilities uint32
+	Flags        uint8
+	Reserved     [3]uint8
+}
+
+// v4l2Timecode mirrors struct v4l2_timecode.
+type v4l2Timecode struct {
+	Type     uint32
+	Flags    uint32
+	Frames   uint8
+	Seconds  uint8
+	Minutes  uint8
+	Hours    uint8
+	Userbits [4]uint8
+}
+
+// v4l2Buffer mirrors struct v4l2_buffer.
+type v4l2Buffer struct {
+	Index     uint32
+	Type      uint32
+	BytesUsed uint32
+	Flags     uint32
+	Field     uint32
+	Timestamp unix.Timeval
+	Timecode  v4l2Timecode
+	Sequence  uint32
+	Memory    uint32
+	M         uintptr // union of offset, userptr, planes and fd
+	Length    uint32
+	Reserved2 uint32
+	RequestFD int32
+}
+
+// offset returns the mmap offset stored in the buffer's memory union.
+func (b *v4l2Buffer) offset() uint32 {
+	return *(*uint32)(unsafe.Pointer(&b.M))
+}
+
+// Control IDs from linux/v4l2-controls.h.
+const (
+	v4l2CIDBase        = 0x00980900
+	v4l2CIDCameraClass = 0x009a0900
+)
+
+// v4l2Controls maps camera properties to V4L2 control IDs. The frame rate is
+// a stream parameter rather than a control.
+var v4l2Controls = map[Property]uint32{
+	PropertyBrightness:       v4l2CIDBase + 0,
+	PropertyContrast:         v4l2CIDBase + 1,
+	PropertySaturation:       v4l2CIDBase + 2,
+	PropertyAutoWhiteBalance: v4l2CIDBase + 12,
+	PropertyGain:             v4l2CIDBase + 19,
+	PropertyWhiteBalance:     v4l2CIDBase + 26,
+	PropertyAutoExposure:     v4l2CIDCameraClass + 1,
+	PropertyExposure:         v4l2CIDCameraClass + 2,
+	PropertyFocus:            v4l2CIDCameraClass + 10,
+	PropertyAutoFocus:        v4l2CIDCameraClass + 12,
+}
+
+// ioctl request encoding from asm-generic/ioctl.h.
+const (
+	iocWrite = 1
+	iocRead  = 2
+)
+
+func ioc(dir, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | 'V'<<8 | nr
+}
+
+// V4L2 ioctl requests.
+var (
+	vidiocQueryCap  = ioc(iocRead, 0, unsafe.Sizeof(v4l2Capability{}))
+	vidiocEnumFmt   = ioc(iocRead|iocWrite, 2, unsafe.Sizeof(v4l2FmtDesc{}))
+	vidiocGFmt      = ioc(iocRead|iocWrite, 4, unsafe.Sizeof(v4l2Format{}))
+	vidiocSFmt      = ioc(iocRead|iocWrite, 5, unsafe.Sizeof(v4l2Format{}))
+	vidiocReqBufs   = ioc(iocRead|iocWrite, 8, unsafe.Sizeof(v4l2RequestBuffers{}))
+	vidiocQueryBuf  = ioc(iocRead|iocWrite, 9, unsafe.Sizeof(v4l2Buffer{}))
+	vidiocQBuf      = ioc(iocRead|iocWrite, 15, unsafe.Sizeof(v4l2Buffer{}))
+	vidiocDQBuf     = ioc(iocRead|iocWrite, 17, unsafe.Sizeof(v4l2Buffer{}))
+	vidiocStreamOn  = ioc(iocWrite, 18, unsafe.Sizeof(int32(0)))
+	vidiocStreamOff = ioc(iocWrite, 19, unsafe.Sizeof(int32(0)))
+	vidiocGParm     = ioc(iocRead|iocWrite, 21, unsafe.Sizeof(v4l2StreamParm{}))
+	vidiocSParm     = ioc(iocRead|iocWrite, 22, unsafe.Sizeof(v4l2StreamParm{}))
+	vidiocGCtrl     = ioc(iocRead|iocWrite, 27, unsafe.Sizeof(v4l2Control{}))
+	vidiocSCtrl     = ioc(iocRead|iocWrite, 28, unsafe.Sizeof(v4l2Control{}))
+
+	vidiocEnumFrameSizes     = ioc(iocRead|iocWrite, 74, unsafe.Sizeof(v4l2FrmSizeEnum{}))
+	vidiocEnumFrameIntervals = ioc(iocRead|iocWrite, 75, unsafe.Sizeof(v4l2FrmIvalEnum{}))
+)
+
+// ioctl issues a V4L2 ioctl, retrying when interrupted by a signal.
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	for {
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+		switch errno {
+		case 0:
+			return nil
+		case unix.EINTR:
+			continue
+		default:
+			return errno
+		}
+	}
+}
+
+// queryCapability returns the capabilities of the device open on fd.
+func queryCapability(fd int) (*v4l2Capability, error) {
+	var c v4l2Capability
+	if err := ioctl(fd, vidiocQueryCap, unsafe.Pointer(&c)); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// caps returns the capabilities of the opened device node, falling back to
+// the capabilities of the physical device on drivers that lack device caps.
+func (c *v4l2Capability) caps() uint32 {
+	if c.Capabilities&v4l2CapDeviceCaps != 0 {
+		return c.DeviceCaps
+	}
+	return c.Capabilities
+}
+
+// cString converts a NUL-terminated byte array to a string.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// fourCC returns the printable form of a V4L2 pixel format code.
+func fourCC(code uint32) string {
+	return string([]byte{byte(code), byte(code >> 8), byte(code >> 16), byte(code >> 24)})
+}
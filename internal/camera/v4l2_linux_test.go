@@ -0,0 +1,65 @@
+package camera
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestV4L2Layout(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("expected values are for 64-bit platforms")
+	}
+
+	sizes := []struct {
+		name string
+		got  uintptr
+		want uintptr
+	}{
+		{"v4l2_capability", unsafe.Sizeof(v4l2Capability{}), 104},
+		{"v4l2_format", unsafe.Sizeof(v4l2Format{}), 208},
+		{"v4l2_requestbuffers", unsafe.Sizeof(v4l2RequestBuffers{}), 20},
+		{"v4l2_buffer", unsafe.Sizeof(v4l2Buffer{}), 88},
+		{"v4l2_fmtdesc", unsafe.Sizeof(v4l2FmtDesc{}), 64},
+		{"v4l2_frmsizeenum", unsafe.Sizeof(v4l2FrmSizeEnum{}), 44},
+		{"v4l2_frmivalenum", unsafe.Sizeof(v4l2FrmIvalEnum{}), 52},
+		{"v4l2_control", unsafe.Sizeof(v4l2Control{}), 8},
+		{"v4l2_streamparm", unsafe.Sizeof(v4l2StreamParm{}), 204},
+	}
+	for _, s := range sizes {
+		if s.got != s.want {
+			t.Errorf("Expected sizeof(%s) = %d, got %d", s.name, s.want, s.got)
+		}
+	}
+
+	requests := []struct {
+		name string
+		got  uintptr
+		want uintptr
+	}{
+		{"VIDIOC_QUERYCAP", vidiocQueryCap, 0x80685600},
+		{"VIDIOC_S_FMT", vidiocSFmt, 0xc0d05605},
+		{"VIDIOC_REQBUFS", vidiocReqBufs, 0xc0145608},
+		{"VIDIOC_QBUF", vidiocQBuf, 0xc058560f},
+		{"VIDIOC_DQBUF", vidiocDQBuf, 0xc0585611},
+		{"VIDIOC_STREAMON", vidiocStreamOn, 0x40045612},
+		{"VIDIOC_ENUM_FMT", vidiocEnumFmt, 0xc0405602},
+		{"VIDIOC_S_PARM", vidiocSParm, 0xc0cc5616},
+		{"VIDIOC_S_CTRL", vidiocSCtrl, 0xc008561c},
+		{"VIDIOC_ENUM_FRAMESIZES", vidiocEnumFrameSizes, 0xc02c564a},
+		{"VIDIOC_ENUM_FRAMEINTERVALS", vidiocEnumFrameIntervals, 0xc034564b},
+	}
+	for _, r := range requests {
+		if r.got != r.want {
+			t.Errorf("Expected %s = %#x, got %#x", r.name, r.want, r.got)
+		}
+	}
+}
+
+func TestFourCC(t *testing.T) {
+	if got := fourCC(pixFmtYUYV); got != "YUYV" {
+		t.Errorf("Expected YUYV, got %q", got)
+	}
+	if got := fourCC(pixFmtMJPEG); got != "MJPG" {
+		t.Errorf("Expected MJPG, got %q", got)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package camera
+
+import (
+	"fmt"
+
+	"github.com/muesli/asciicam/internal/errors"
+)
+
+// NewV4L2Capture always fails on platforms other than Linux.
+func NewV4L2Capture(deviceID int, _, _ uint) (FrameSource, error) {
+	return nil, errors.NewCameraError(deviceID, "open", fmt.Errorf("%w: V4L2 is only available on Linux", errors.ErrCameraUnsupported))
+}
@@ -0,0 +1,330 @@
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"unsafe"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// v4l2BufferCount is the number of mmap buffers requested from the driver.
+	v4l2BufferCount = 4
+	// v4l2PollTimeout bounds how long a read waits before re-checking the context.
+	v4l2PollTimeout = 100 // milliseconds
+)
+
+// V4L2Capture captures frames from a Video4Linux2 device using mmap
+// streaming I/O. It is implemented in pure Go and does not need OpenCV or cgo.
+type V4L2Capture struct {
+	fd          int
+	deviceID    int
+	width       uint
+	height      uint
+	stride      int // bytes per row of YUYV frames
+	pixelFormat uint32
+	buffers     [][]byte
+	streaming   bool
+}
+
+var (
+	_ FrameSource    = (*V4L2Capture)(nil)
+	_ PropertySetter = (*V4L2Capture)(nil)
+)
+
+// NewV4L2Capture opens /dev/video<deviceID> and starts streaming at the
+// requested size, or the closest size the driver supports. YUYV is preferred
+// for small frames and MJPEG for large ones, where most webcams only reach
+// usable frame rates with compression.
+func NewV4L2Capture(deviceID int, width, height uint) (*V4L2Capture, error) {
+	path := fmt.Sprintf("/dev/video%d", deviceID)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, errors.NewCameraError(deviceID, "open", fmt.Errorf("%w: %s: %v", errors.ErrCameraNotFound, path, err))
+	}
+
+	c := &V4L2Capture{fd: fd, deviceID: deviceID}
+	if err := c.init(width, height); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// init checks the device's capabilities, negotiates a format and starts streaming.
+func (c *V4L2Capture) init(width, height uint) error {
+	capability, err := queryCapability(c.fd)
+	if err != nil {
+		return errors.NewCameraError(c.deviceID, "querycap", fmt.Errorf("%w: %v", errors.ErrCameraUnsupported, err))
+	}
+	if caps := capability.caps(); caps&v4l2CapVideoCapture == 0 || caps&v4l2CapStreaming == 0 {
+		return errors.NewCameraError(c.deviceID, "querycap", fmt.Errorf("%w: %s does not support streaming video capture", errors.ErrCameraUnsupported, cString(capability.Card[:])))
+	}
+
+	if err := c.setFormat(width, height); err != nil {
+		return err
+	}
+
+	return c.startStreaming()
+}
+
+// setFormat negotiates the frame size and a pixel format we can decode.
+func (c *V4L2Capture) setFormat(width, height uint) error {
+	formats := []uint32{pixFmtYUYV, pixFmtMJPEG}
+	if width*height > 640*480 {
+		formats = []uint32{pixFmtMJPEG, pixFmtYUYV}
+	}
+
+	for _, pixelFormat := range formats {
+		f := v4l2Format{Type: v4l2BufTypeVideoCapture}
+		pix := f.pix()
+		pix.Width = uint32(width)
+		pix.Height = uint32(height)
+		pix.PixelFormat = pixelFormat
+		pix.Field = v4l2FieldAny
+
+		if err := ioctl(c.fd, vidiocSFmt, unsafe.Pointer(&f)); err != nil {
+			return errors.NewCameraError(c.deviceID, "setformat", fmt.Errorf("%w: %v", errors.ErrCameraInitFailed, err))
+		}
+
+		// The driver replaces unsupported formats with one it supports
+		if pix.PixelFormat == pixFmtYUYV || pix.PixelFormat == pixFmtMJPEG {
+			c.width, c.height = uint(pix.Width), uint(pix.Height)
+			c.pixelFormat = pix.PixelFormat
+			// Drivers may pad rows; 0 means they are not
+			c.stride = int(pix.BytesPerLine)
+			if c.stride == 0 {
+				c.stride = int(pix.Width) * 2
+			}
+			return nil
+		}
+	}
+
+	return errors.NewCameraError(c.deviceID, "setformat", fmt.Errorf("%w: device supports neither YUYV nor MJPEG", errors.ErrCameraUnsupported))
+}
+
+// startStreaming maps the driver's buffers, queues them and starts capture.
+func (c *V4L2Capture) startStreaming() error {
+	req := v4l2RequestBuffers{
+		Count:  v4l2BufferCount,
+		Type:   v4l2BufTypeVideoCapture,
+		Memory: v4l2MemoryMmap,
+	}
+	if err := ioctl(c.fd, vidiocReqBufs, unsafe.Pointer(&req)); err != nil {
+		return errors.NewCameraError(c.deviceID, "reqbufs", fmt.Errorf("%w: %v", errors.ErrCameraInitFailed, err))
+	}
+	if req.Count == 0 {
+		return errors.NewCameraError(c.deviceID, "reqbufs", fmt.Errorf("%w: no buffers available", errors.ErrCameraInitFailed))
+	}
+
+	for i := uint32(0); i < req.Count; i++ {
+		buf := v4l2Buffer{
+			Index:  i,
+			Type:   v4l2BufTypeVideoCapture,
+			Memory: v4l2MemoryMmap,
+		}
+		if err := ioctl(c.fd, vidiocQueryBuf, unsafe.Pointer(&buf)); err != nil {
+			return errors.NewCameraError(c.deviceID, "querybuf", fmt.Errorf("%w: %v", errors.ErrCameraInitFailed, err))
+		}
+
+		data, err := unix.Mmap(c.fd, int64(buf.offset()), int(buf.Length), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+		if err != nil {
+			return errors.NewCameraError(c.deviceID, "mmap", fmt.Errorf("%w: %v", errors.ErrCameraInitFailed, err))
+		}
+		c.buffers = append(c.buffers, data)
+
+		if err := ioctl(c.fd, vidiocQBuf, unsafe.Pointer(&buf)); err != nil {
+			return errors.NewCameraError(c.deviceID, "qbuf", fmt.Errorf("%w: %v", errors.ErrCameraInitFailed, err))
+		}
+	}
+
+	typ := int32(v4l2BufTypeVideoCapture)
+	if err := ioctl(c.fd, vidiocStreamOn, unsafe.Pointer(&typ)); err != nil {
+		return errors.NewCameraError(c.deviceID, "streamon", fmt.Errorf("%w: %v", errors.ErrCameraInitFailed, err))
+	}
+	c.streaming = true
+
+	return nil
+}
+
+// stopStreaming stops capture and releases the driver's buffers, so the
+// stream parameters can be changed.
+func (c *V4L2Capture) stopStreaming() {
+	if c.streaming {
+		typ := int32(v4l2BufTypeVideoCapture)
+		_ = ioctl(c.fd, vidiocStreamOff, unsafe.Pointer(&typ))
+		c.streaming = false
+	}
+
+	for _, b := range c.buffers {
+		_ = unix.Munmap(b)
+	}
+	if c.buffers != nil {
+		req := v4l2RequestBuffers{Type: v4l2BufTypeVideoCapture, Memory: v4l2MemoryMmap}
+		_ = ioctl(c.fd, vidiocReqBufs, unsafe.Pointer(&req))
+	}
+	c.buffers = nil
+}
+
+// SetProperties applies camera properties as V4L2 controls and reads back
+// the values the driver settled on. Changing the frame rate briefly restarts
+// the stream.
+func (c *V4L2Capture) SetProperties(props Properties) []PropertyResult {
+	props, order := props.resolve()
+
+	results := make([]PropertyResult, 0, len(order))
+	for _, prop := range order {
+		value := props[prop]
+		result := PropertyResult{Property: prop, Requested: value}
+
+		switch prop {
+		case PropertyFPS:
+			result.Actual, result.Err = c.setFrameRate(value)
+		case PropertyAutoExposure:
+			var mode float64
+			mode, result.Err = c.setControl(v4l2Controls[prop], exposureMode(value))
+			result.Actual = autoExposure(mode)
+		default:
+			result.Actual, result.Err = c.setControl(v4l2Controls[prop], value)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// setControl sets a V4L2 control and returns the value the driver reports
+// back for it.
+func (c *V4L2Capture) setControl(id uint32, value float64) (float64, error) {
+	ctrl := v4l2Control{ID: id, Value: int32(math.Round(value))}
+	if err := ioctl(c.fd, vidiocSCtrl, unsafe.Pointer(&ctrl)); err != nil {
+		return 0, errors.NewCameraError(c.deviceID, "setcontrol", fmt.Errorf("%w: %v", errors.ErrCameraUnsupported, err))
+	}
+
+	ctrl = v4l2Control{ID: id}
+	if err := ioctl(c.fd, vidiocGCtrl, unsafe.Pointer(&ctrl)); err != nil {
+		return 0, errors.NewCameraError(c.deviceID, "getcontrol", fmt.Errorf("%w: %v", errors.ErrCameraReadFailed, err))
+	}
+
+	return float64(ctrl.Value), nil
+}
+
+// setFrameRate restarts the stream at the given frame rate and returns the
+// frame rate the driver chose.
+func (c *V4L2Capture) setFrameRate(fps float64) (float64, error) {
+	if fps <= 0 {
+		return 0, errors.NewCameraError(c.deviceID, "setparm", fmt.Errorf("%w: frame rate %g", errors.ErrInvalidConfig, fps))
+	}
+
+	parm := v4l2StreamParm{Type: v4l2BufTypeVideoCapture}
+	if err := ioctl(c.fd, vidiocGParm, unsafe.Pointer(&parm)); err != nil || parm.capture().Capability&v4l2CapTimePerFrame == 0 {
+		return 0, errors.NewCameraError(c.deviceID, "setparm", fmt.Errorf("%w: frame rate cannot be set", errors.ErrCameraUnsupported))
+	}
+
+	c.stopStreaming()
+
+	capture := parm.capture()
+	capture.TimePerFrame.Numerator = 1000
+	capture.TimePerFrame.Denominator = uint32(math.Round(fps * 1000))
+	err := ioctl(c.fd, vidiocSParm, unsafe.Pointer(&parm))
+
+	// Restart even if the driver refused, so capture continues
+	if serr := c.startStreaming(); serr != nil {
+		return 0, serr
+	}
+	if err != nil {
+		return 0, errors.NewCameraError(c.deviceID, "setparm", fmt.Errorf("%w: %v", errors.ErrCameraUnsupported, err))
+	}
+
+	return frameRate(capture.TimePerFrame.Numerator, capture.TimePerFrame.Denominator), nil
+}
+
+// Close stops streaming, unmaps the buffers and closes the device.
+func (c *V4L2Capture) Close() {
+	c.stopStreaming()
+
+	if c.fd >= 0 {
+		_ = unix.Close(c.fd)
+		c.fd = -1
+	}
+}
+
+// Dimensions returns the frame size negotiated with the driver.
+func (c *V4L2Capture) Dimensions() (uint, uint) {
+	return c.width, c.height
+}
+
+// GetDeviceID returns the device ID of the camera.
+func (c *V4L2Capture) GetDeviceID() int {
+	return c.deviceID
+}
+
+// PixelFormat returns the fourcc code of the negotiated pixel format.
+func (c *V4L2Capture) PixelFormat() string {
+	return fourCC(c.pixelFormat)
+}
+
+// ReadFrameWithContext waits for the next filled buffer and decodes it.
+func (c *V4L2Capture) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	buf := v4l2Buffer{
+		Type:   v4l2BufTypeVideoCapture,
+		Memory: v4l2MemoryMmap,
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("context cancelled: %w", err)
+		}
+
+		fds := []unix.PollFd{{Fd: int32(c.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, v4l2PollTimeout)
+		if err == unix.EINTR || (err == nil && n == 0) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.NewCameraError(c.deviceID, "poll", fmt.Errorf("%w: %v", errors.ErrCameraReadFailed, err))
+		}
+		if fds[0].Revents&(unix.POLLERR|unix.POLLHUP|unix.POLLNVAL) != 0 {
+			return nil, errors.NewCameraError(c.deviceID, "poll", fmt.Errorf("%w: device error", errors.ErrCameraReadFailed))
+		}
+
+		err = ioctl(c.fd, vidiocDQBuf, unsafe.Pointer(&buf))
+		if err == unix.EAGAIN {
+			continue
+		}
+		if err != nil {
+			return nil, errors.NewCameraError(c.deviceID, "dqbuf", fmt.Errorf("%w: %v", errors.ErrCameraReadFailed, err))
+		}
+		break
+	}
+
+	if int(buf.Index) >= len(c.buffers) {
+		return nil, errors.NewCameraError(c.deviceID, "dqbuf", fmt.Errorf("%w: unknown buffer %d", errors.ErrCameraReadFailed, buf.Index))
+	}
+
+	// Decode before handing the buffer back to the driver
+	data := c.buffers[buf.Index][:buf.BytesUsed]
+	var img *image.RGBA
+	var err error
+	if c.pixelFormat == pixFmtMJPEG {
+		img, err = decodeMJPEG(data)
+	} else {
+		img, err = decodeYUYV(data, int(c.width), int(c.height), c.stride)
+	}
+
+	if qerr := ioctl(c.fd, vidiocQBuf, unsafe.Pointer(&buf)); qerr != nil {
+		return nil, errors.NewCameraError(c.deviceID, "qbuf", fmt.Errorf("%w: %v", errors.ErrCameraReadFailed, qerr))
+	}
+	if err != nil {
+		// Corrupt frames happen, the next one is usually fine
+		return nil, errors.NewCameraError(c.deviceID, "decode", fmt.Errorf("%w: %v", errors.ErrCameraReadFailed, err))
+	}
+
+	return img, nil
+}
@@ -0,0 +1,150 @@
+//go:build cgo && !noopencv
+
+package camera
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"gocv.io/x/gocv"
+)
+
+// defaultVideoFPS is used when a video file does not report its frame rate.
+const defaultVideoFPS = 30.0
+
+// VideoFile plays back a local video file at its native frame rate.
+// It is not safe for concurrent use; controls such as Seek and TogglePause
+// must be called from the same goroutine that reads frames.
+type VideoFile struct {
+	video  *gocv.VideoCapture
+	path   string
+	width  uint
+	height uint
+	pace   pacer
+	loop   bool
+
+	paused  bool
+	refresh bool
+	last    image.Image
+}
+
+var (
+	_ FrameSource = (*VideoFile)(nil)
+	_ Pauser      = (*VideoFile)(nil)
+	_ Seeker      = (*VideoFile)(nil)
+)
+
+// NewVideoFile opens a video file for playback. If loop is true, playback
+// restarts from the beginning once the end of the file is reached.
+func NewVideoFile(path string, loop bool) (*VideoFile, error) {
+	video, err := gocv.VideoCaptureFile(path)
+	if err != nil {
+		return nil, errors.NewFileError(path, "open", fmt.Errorf("%w: %v", errors.ErrFileReadFailed, err))
+	}
+
+	if !video.IsOpened() {
+		video.Close()
+		return nil, errors.NewFileError(path, "open", errors.ErrFileReadFailed)
+	}
+
+	fps := video.Get(gocv.VideoCaptureFPS)
+	if fps <= 0 {
+		fps = defaultVideoFPS
+	}
+
+	return &VideoFile{
+		video:  video,
+		path:   path,
+		width:  uint(video.Get(gocv.VideoCaptureFrameWidth)),
+		height: uint(video.Get(gocv.VideoCaptureFrameHeight)),
+		pace:   newPacer(fps),
+		loop:   loop,
+	}, nil
+}
+
+// Close closes the video file.
+func (v *VideoFile) Close() {
+	if v.video != nil {
+		v.video.Close()
+	}
+}
+
+// Dimensions returns the width and height of the video.
+func (v *VideoFile) Dimensions() (uint, uint) {
+	return v.width, v.height
+}
+
+// FrameDelay returns the time between two frames at the video's native frame rate.
+func (v *VideoFile) FrameDelay() time.Duration {
+	return v.pace.delay
+}
+
+// TogglePause pauses or resumes playback and reports whether playback is now paused.
+func (v *VideoFile) TogglePause() bool {
+	v.paused = !v.paused
+	if !v.paused {
+		// Resume from now rather than trying to catch up on the paused time
+		v.pace.reset()
+	}
+	return v.paused
+}
+
+// Seek moves the playback position by offset, clamped to the start of the file.
+func (v *VideoFile) Seek(offset time.Duration) error {
+	pos := v.video.Get(gocv.VideoCapturePosMsec) + float64(offset.Milliseconds())
+	if pos < 0 {
+		pos = 0
+	}
+	v.video.Set(gocv.VideoCapturePosMsec, pos)
+
+	// Show the new position right away, even while paused
+	v.refresh = true
+	v.pace.reset()
+	return nil
+}
+
+// ReadFrameWithContext returns the next frame of the video, sleeping as needed
+// to honor the native frame rate. Frames are skipped when the caller falls
+// behind. Once the end of the file is reached without looping, an error
+// wrapping errors.ErrEndOfStream is returned.
+func (v *VideoFile) ReadFrameWithContext(ctx context.Context) (image.Image, error) {
+	if err := v.pace.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if v.paused && !v.refresh && v.last != nil {
+		return v.last, nil
+	}
+	v.refresh = false
+
+	// Drop frames we are too late for to keep playback in real time
+	if n := v.pace.behind(); n > 0 {
+		v.video.Grab(n)
+	}
+
+	frame := gocv.NewMat()
+	defer frame.Close()
+
+	if ok := v.video.Read(&frame); !ok || frame.Empty() {
+		if !v.loop {
+			return nil, errors.NewFileError(v.path, "read", errors.ErrEndOfStream)
+		}
+
+		// Rewind and try once more
+		v.video.Set(gocv.VideoCapturePosFrames, 0)
+		if ok := v.video.Read(&frame); !ok || frame.Empty() {
+			return nil, errors.NewFileError(v.path, "read", errors.ErrEndOfStream)
+		}
+	}
+
+	img, err := matToRGBA(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	v.last = img
+	return img, nil
+}
@@ -0,0 +1,65 @@
+//go:build cgo && !noopencv
+
+package camera
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"gocv.io/x/gocv"
+)
+
+func TestNewVideoFile_NotFound(t *testing.T) {
+	_, err := NewVideoFile("does-not-exist.mp4", false)
+	if err == nil {
+		t.Fatal("Expected error for missing video file, got none")
+	}
+
+	if _, ok := err.(*errors.FileError); !ok {
+		t.Errorf("Expected *errors.FileError, got %T", err)
+	}
+}
+
+func TestVideoFile_TogglePause(t *testing.T) {
+	v := &VideoFile{pace: pacer{delay: 40 * time.Millisecond, next: time.Now()}}
+
+	if !v.TogglePause() {
+		t.Error("Expected video to be paused after first toggle")
+	}
+
+	if v.TogglePause() {
+		t.Error("Expected video to be resumed after second toggle")
+	}
+
+	if !v.pace.next.IsZero() {
+		t.Error("Expected frame timer to reset on resume")
+	}
+}
+
+func TestMatToRGBA(t *testing.T) {
+	mat := gocv.NewMatWithSize(3, 4, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	img, err := matToRGBA(mat)
+	if err != nil {
+		t.Fatalf("matToRGBA returned error: %v", err)
+	}
+
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 3 {
+		t.Errorf("Expected 4x3 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	if img.Pix[3] != 255 {
+		t.Errorf("Expected opaque pixels, got alpha %d", img.Pix[3])
+	}
+}
+
+func TestMatToRGBA_Empty(t *testing.T) {
+	mat := gocv.NewMat()
+	defer mat.Close()
+
+	if _, err := matToRGBA(mat); err == nil {
+		t.Error("Expected error for empty Mat")
+	}
+}
@@ -0,0 +1,133 @@
+package camera
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// MaxMagnification is the strongest digital zoom a Viewport allows.
+	MaxMagnification = 8.0
+	// viewportSmoothing is the fraction of the remaining distance to its
+	// target the viewport covers per frame.
+	viewportSmoothing = 0.3
+	// viewportSnap is how close to its target the viewport has to get
+	// before it stops moving.
+	viewportSnap = 1e-3
+)
+
+// Viewport implements digital pan and zoom: it crops a region of interest
+// out of each frame, which is then scaled up to fill the terminal. The region
+// is described by its center, as fractions of the frame size, and a
+// magnification, where 1 shows the whole frame. Changes glide towards their
+// target over a few frames instead of jumping.
+//
+// Viewport is not safe for concurrent use.
+type Viewport struct {
+	x, y, zoom                   float64 // current view
+	targetX, targetY, targetZoom float64
+	homeX, homeY, homeZoom       float64 // view restored by Reset
+}
+
+// NewViewport returns a viewport centered on x, y magnified by zoom. The
+// initial view is shown right away, without a transition.
+func NewViewport(x, y, zoom float64) *Viewport {
+	v := &Viewport{}
+	v.SetView(x, y, zoom)
+	v.x, v.y, v.zoom = v.targetX, v.targetY, v.targetZoom
+	v.homeX, v.homeY, v.homeZoom = v.targetX, v.targetY, v.targetZoom
+	return v
+}
+
+// SetView moves the viewport towards a new center and magnification. The
+// center is kept far enough from the edges for the region to fit the frame.
+func (v *Viewport) SetView(x, y, zoom float64) {
+	v.targetZoom = math.Max(1, math.Min(zoom, MaxMagnification))
+	half := 0.5 / v.targetZoom
+	v.targetX = math.Max(half, math.Min(x, 1-half))
+	v.targetY = math.Max(half, math.Min(y, 1-half))
+}
+
+// Pan moves the viewport by dx, dy, in fractions of the visible region.
+func (v *Viewport) Pan(dx, dy float64) {
+	v.SetView(v.targetX+dx/v.targetZoom, v.targetY+dy/v.targetZoom, v.targetZoom)
+}
+
+// Zoom multiplies the magnification by factor, keeping the center.
+func (v *Viewport) Zoom(factor float64) {
+	v.SetView(v.targetX, v.targetY, v.targetZoom*factor)
+}
+
+// CenterOn moves the center of the viewport to x, y, keeping the
+// magnification.
+func (v *Viewport) CenterOn(x, y float64) {
+	v.SetView(x, y, v.targetZoom)
+}
+
+// Reset returns to the view the viewport was created with.
+func (v *Viewport) Reset() {
+	v.SetView(v.homeX, v.homeY, v.homeZoom)
+}
+
+// Magnification returns the current magnification.
+func (v *Viewport) Magnification() float64 {
+	return v.zoom
+}
+
+// step moves the current view one frame closer to the target.
+func (v *Viewport) step() {
+	v.x = approach(v.x, v.targetX)
+	v.y = approach(v.y, v.targetY)
+	v.zoom = approach(v.zoom, v.targetZoom)
+}
+
+// approach returns value moved towards target by viewportSmoothing, or target
+// itself once they are close.
+func approach(value, target float64) float64 {
+	if math.Abs(target-value) < viewportSnap {
+		return target
+	}
+	return value + (target-value)*viewportSmoothing
+}
+
+// Region returns the part of a frame with the given bounds that the viewport
+// currently shows.
+func (v *Viewport) Region(bounds image.Rectangle) image.Rectangle {
+	fw, fh := float64(bounds.Dx()), float64(bounds.Dy())
+	w := max(int(math.Round(fw/v.zoom)), 1)
+	h := max(int(math.Round(fh/v.zoom)), 1)
+
+	x0 := int(math.Round(v.x*fw - float64(w)/2))
+	y0 := int(math.Round(v.y*fh - float64(h)/2))
+	x0 = max(0, min(x0, bounds.Dx()-w))
+	y0 = max(0, min(y0, bounds.Dy()-h))
+
+	return image.Rect(x0, y0, x0+w, y0+h).Add(bounds.Min)
+}
+
+// Crop advances any ongoing transition by one frame and returns the region
+// of img the viewport shows. The region shares its pixels with img where the
+// image type allows it. img is returned as is at a magnification of 1.
+func (v *Viewport) Crop(img image.Image) image.Image {
+	v.step()
+	if v.zoom == 1 {
+		return img
+	}
+
+	return cropImage(img, v.Region(img.Bounds()))
+}
+
+// FaceDetector finds faces in frames, so the viewport can follow them.
+type FaceDetector interface {
+	// DetectFace returns the bounds of the largest face in img.
+	DetectFace(img image.Image) (image.Rectangle, bool)
+	// Close releases the resources held by the detector.
+	Close()
+}
+
+// FollowFace centers the viewport on face, a region of a frame with the
+// given bounds.
+func (v *Viewport) FollowFace(bounds, face image.Rectangle) {
+	c := face.Min.Add(face.Max).Div(2).Sub(bounds.Min)
+	v.CenterOn(float64(c.X)/float64(bounds.Dx()), float64(c.Y)/float64(bounds.Dy()))
+}
@@ -0,0 +1,126 @@
+package camera
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// settle runs the viewport's transition to its end.
+func settle(v *Viewport) {
+	for i := 0; i < 100; i++ {
+		v.step()
+	}
+}
+
+func TestViewport_Region(t *testing.T) {
+	bounds := image.Rect(0, 0, 200, 100)
+
+	tests := []struct {
+		name    string
+		x, y    float64
+		zoom    float64
+		want    image.Rectangle
+		wantMag float64
+	}{
+		{"whole frame", 0.5, 0.5, 1, image.Rect(0, 0, 200, 100), 1},
+		{"centered", 0.5, 0.5, 2, image.Rect(50, 25, 150, 75), 2},
+		{"top left", 0.25, 0.25, 2, image.Rect(0, 0, 100, 50), 2},
+		{"clamped to the edge", 1, 1, 4, image.Rect(150, 75, 200, 100), 4},
+		{"magnification capped", 0.5, 0.5, 100, image.Rect(88, 44, 113, 57), MaxMagnification},
+		{"magnification below one", 0.5, 0.5, 0.5, image.Rect(0, 0, 200, 100), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewViewport(tt.x, tt.y, tt.zoom)
+			if got := v.Region(bounds); got != tt.want {
+				t.Errorf("Region() = %v, want %v", got, tt.want)
+			}
+			if got := v.Magnification(); got != tt.wantMag {
+				t.Errorf("Magnification() = %g, want %g", got, tt.wantMag)
+			}
+		})
+	}
+}
+
+func TestViewport_RegionOffsetBounds(t *testing.T) {
+	v := NewViewport(0.5, 0.5, 2)
+	got := v.Region(image.Rect(10, 20, 210, 120))
+	if want := image.Rect(60, 45, 160, 95); got != want {
+		t.Errorf("Region() = %v, want %v", got, want)
+	}
+}
+
+func TestViewport_SmoothTransition(t *testing.T) {
+	v := NewViewport(0.5, 0.5, 1)
+	v.Zoom(4)
+
+	// The magnification glides towards the target instead of jumping
+	v.step()
+	if z := v.Magnification(); z <= 1 || z >= 4 {
+		t.Fatalf("Expected magnification between 1 and 4 after one frame, got %g", z)
+	}
+
+	settle(v)
+	if z := v.Magnification(); z != 4 {
+		t.Errorf("Expected magnification to settle at 4, got %g", z)
+	}
+}
+
+func TestViewport_PanAndReset(t *testing.T) {
+	v := NewViewport(0.5, 0.5, 2)
+	bounds := image.Rect(0, 0, 200, 100)
+
+	v.Pan(0.5, 0)
+	settle(v)
+	// Half the visible width to the right, which is a quarter of the frame
+	if got, want := v.Region(bounds), image.Rect(100, 25, 200, 75); got != want {
+		t.Errorf("Region() after pan = %v, want %v", got, want)
+	}
+
+	// Panning past the edge stops at the edge
+	v.Pan(1, 0)
+	settle(v)
+	if got, want := v.Region(bounds), image.Rect(100, 25, 200, 75); got != want {
+		t.Errorf("Region() past the edge = %v, want %v", got, want)
+	}
+
+	v.Reset()
+	settle(v)
+	if got, want := v.Region(bounds), image.Rect(50, 25, 150, 75); got != want {
+		t.Errorf("Region() after reset = %v, want %v", got, want)
+	}
+}
+
+func TestViewport_FollowFace(t *testing.T) {
+	v := NewViewport(0.5, 0.5, 4)
+	v.FollowFace(image.Rect(0, 0, 400, 200), image.Rect(20, 140, 60, 180))
+	settle(v)
+
+	if math.Abs(v.x-0.125) > 1e-9 || math.Abs(v.y-0.8) > 1e-9 {
+		t.Errorf("Expected center 0.125,0.8, got %g,%g", v.x, v.y)
+	}
+}
+
+func TestViewport_Crop(t *testing.T) {
+	img := numberedImage(4, 2)
+
+	if got := NewViewport(0.5, 0.5, 1).Crop(img); got != img {
+		t.Error("Expected the whole frame at magnification 1")
+	}
+
+	got := NewViewport(0, 0, 2).Crop(img)
+	if b := got.Bounds(); b != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("Expected 2x1 region, got %v", b)
+	}
+	if rows := pixelRows(got); string(rows[0]) != string([]uint8{1, 2}) {
+		t.Errorf("Expected pixels 1 2, got %v", rows[0])
+	}
+
+	// Images without SubImage are copied
+	plain := struct{ image.Image }{img}
+	if b := NewViewport(1, 1, 2).Crop(plain).Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+		t.Errorf("Expected 2x1 copy, got %v", b)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package config
+
+// detectCellAspect is not supported on this platform.
+func detectCellAspect() (float64, bool) {
+	return 0, false
+}
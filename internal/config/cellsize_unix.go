@@ -0,0 +1,19 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package config
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// detectCellAspect asks the terminal on stdout for its size in cells and in
+// pixels to work out the shape of its cells.
+func detectCellAspect() (float64, bool) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, false
+	}
+	return cellAspect(ws.Col, ws.Row, ws.Xpixel, ws.Ypixel)
+}
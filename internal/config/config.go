@@ -2,12 +2,22 @@
 package config
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
 	"image/color"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/asciicam/internal/ascii"
+	"github.com/muesli/asciicam/internal/errors"
 	"golang.org/x/term"
 )
 
@@ -17,16 +27,62 @@ type Config struct {
 	DeviceID  int
 	CamWidth  uint
 	CamHeight uint
+	Backend   string // capture backend: auto, opencv or v4l2
+
+	// Camera properties, only applied when set on the command line
+	Properties CameraProperties
+
+	// Input settings
+	Input    string        // video file, image, image glob, test pattern or "-" for stdin to play instead of the camera
+	Loop     bool          // restart playback at the end of the input
+	Interval time.Duration // how long each still image is shown in a slideshow
+	Once     bool          // render a single frame to stdout and exit
+
+	// Orientation settings, applied to frames before resizing
+	Mirror bool // swap left and right, e.g. for a self-view
+	Flip   bool // swap top and bottom
+	Rotate int  // clockwise rotation in degrees: 0, 90, 180 or 270
+
+	// Digital zoom settings: the region of interest cropped from each frame
+	Zoom        float64 // magnification, 1 shows the whole frame
+	CenterX     float64 // center of the region, as a fraction of the frame width
+	CenterY     float64 // center of the region, as a fraction of the frame height
+	FaceCascade string  // OpenCV Haar cascade used to keep the region centered on a face
 
 	// Display settings
 	Width  uint
 	Height uint
-	Zoom   uint
+	Shrink uint // output size as a fraction of the terminal, in quarters
+
+	// Scaling settings
+	Scale      string  // how frames fit the output: fit, fill or stretch
+	CellAspect float64 // height to width ratio of terminal cells, 0 detects it
 
 	// Rendering settings
-	ANSI    bool
-	Color   string
-	ShowFPS bool
+	ANSI          bool
+	Blocks        string // block shape in ANSI mode: half, quadrant or sextant
+	Color         string
+	ShowFPS       bool
+	Charset       string  // preset name or "custom:" followed by the ramp
+	InvertRamp    bool    // use the densest glyphs for dark pixels, for light backgrounds
+	Shapes        bool    // pick glyphs by the shape of each cell's contents rather than its brightness
+	Edges         bool    // draw edges as lines over a faded image
+	EdgeThreshold float64 // gradient strength edges need, 1 being a step from black to white
+	Braille       bool    // draw 2x4 pixels per cell as braille dots
+	BrailleDither bool    // raise braille dots by ordered dithering instead of an adaptive threshold
+	Dither        string  // dithering of glyphs and of colors in 16 and 256 color terminals
+
+	// Output settings
+	Delta          bool    // redraw only the cells that changed since the previous frame
+	DeltaThreshold float64 // color difference cells may drift by before they are redrawn
+
+	// Image adjustments, applied to frames after scaling them
+	AdjustBrightness float64 // added to every channel, from -1 to 1
+	AdjustContrast   float64 // factor for the distance from mid gray
+	AdjustGamma      float64 // above 1 brightens midtones, below 1 darkens them
+	AdjustSaturation float64 // factor for the distance from gray, 0 is grayscale
+	AdjustHue        float64 // rotation of hues in degrees
+	AutoLevels       string  // automatic levels: none, stretch or equalize
 
 	// Greenscreen settings
 	GenerateSamples bool
@@ -36,6 +92,84 @@ type Config struct {
 
 	// Parsed color (internal use)
 	ParsedColor color.Color
+
+	// Settings detected from the terminal rather than set explicitly, which
+	// follow the terminal when it is resized
+	autoWidth      bool
+	autoHeight     bool
+	autoCellAspect bool
+
+	// Whether -camWidth and -camHeight were both given, which headerless
+	// frames on stdin need
+	camSizeSet bool
+
+	// Standard input, buffered so Validate can look for a Y4M header
+	// without losing it
+	stdin *bufio.Reader
+}
+
+const (
+	// StdinInput is the Input value that reads raw frames from stdin.
+	StdinInput = "-"
+	// y4mMagic starts a YUV4MPEG2 stream, matching the camera package.
+	y4mMagic = "YUV4MPEG2"
+	// TestPatternPrefix selects a synthetic test pattern, e.g. "testpattern:bars".
+	TestPatternPrefix = "testpattern:"
+
+	// MaxZoom is the strongest digital zoom, matching camera.MaxMagnification.
+	MaxZoom = 8.0
+
+	// ScaleFit letterboxes frames to keep their aspect ratio.
+	ScaleFit = "fit"
+	// ScaleFill crops frames to cover the output and keep their aspect ratio.
+	ScaleFill = "fill"
+	// ScaleStretch stretches frames to the output size.
+	ScaleStretch = "stretch"
+
+	// AutoLevelsNone leaves the levels of frames as they are.
+	AutoLevelsNone = "none"
+	// AutoLevelsStretch stretches the darkest and brightest parts of frames
+	// to black and white.
+	AutoLevelsStretch = "stretch"
+	// AutoLevelsEqualize equalizes the histogram of frames.
+	AutoLevelsEqualize = "equalize"
+
+	// Limits of the image adjustments, matching those of camera.Adjuster.
+	MaxContrast   = 4.0
+	MinGamma      = 0.1
+	MaxGamma      = 10.0
+	MaxSaturation = 4.0
+
+	// defaultCellAspect is the typical height to width ratio of terminal
+	// cells, used when the terminal does not report its pixel size.
+	defaultCellAspect = 2.0
+
+	// BackendAuto uses OpenCV when the binary was built with it and V4L2 otherwise.
+	BackendAuto = "auto"
+	// BackendOpenCV captures through OpenCV.
+	BackendOpenCV = "opencv"
+	// BackendV4L2 captures through the pure Go Video4Linux2 backend (Linux only).
+	BackendV4L2 = "v4l2"
+)
+
+// testPatterns are the names of the test patterns, matching
+// camera.TestPatterns.
+var testPatterns = []string{"bars", "bounce", "checkerboard", "gradient", "noise"}
+
+// CameraProperties holds the camera properties requested on the command
+// line. Nil fields leave the camera's own setting untouched.
+type CameraProperties struct {
+	AutoExposure     *bool
+	Exposure         *float64
+	Gain             *float64
+	AutoFocus        *bool
+	Focus            *float64
+	Brightness       *float64
+	Contrast         *float64
+	Saturation       *float64
+	AutoWhiteBalance *bool
+	WhiteBalance     *float64 // color temperature in Kelvin
+	FPS              *float64
 }
 
 // NewConfig creates a new configuration with default values.
@@ -44,52 +178,198 @@ func NewConfig() *Config {
 		DeviceID:        0,
 		CamWidth:        1920,
 		CamHeight:       1080,
+		Backend:         BackendAuto,
+		Input:           "", // Use camera
+		Loop:            false,
+		Interval:        time.Second,
+		Once:            false,
+		Mirror:          false,
+		Flip:            false,
+		Rotate:          0,
+		Zoom:            1,
+		CenterX:         0.5,
+		CenterY:         0.5,
+		FaceCascade:     "",
 		Width:           0, // Auto-detect
 		Height:          0, // Auto-detect
-		Zoom:            4,
+		Shrink:          4,
+		Scale:           ScaleFit,
+		CellAspect:      0, // Auto-detect
 		ANSI:            false,
+		Blocks:          string(ascii.BlocksHalf),
 		Color:           "",
 		ShowFPS:         false,
+		Charset:         ascii.DefaultCharset,
+		InvertRamp:      false,
+		Shapes:          false,
+		Edges:           false,
+		EdgeThreshold:   0.2,
+		Braille:         false,
+		BrailleDither:   false,
+		Dither:          string(ascii.DitherNone),
 		GenerateSamples: false,
 		UseGreenscreen:  false,
 		SamplePath:      "bgsample",
 		Threshold:       0.13,
 		ParsedColor:     color.RGBA{0, 0, 0, 0}, // Alpha 0 means use truecolor
+
+		// Redraw changed cells, leaving barely noticeable color changes
+		Delta:          true,
+		DeltaThreshold: 0.02,
+
+		// Image adjustments that leave frames as they are
+		AdjustBrightness: 0,
+		AdjustContrast:   1,
+		AdjustGamma:      1,
+		AdjustSaturation: 1,
+		AdjustHue:        0,
+		AutoLevels:       AutoLevelsNone,
 	}
 }
 
 // ParseFlags parses command line flags and updates the configuration.
 func (c *Config) ParseFlags() error {
 	deviceID := flag.Int("dev", c.DeviceID, "camera device ID (default: 0)")
+	backend := flag.String("backend", c.Backend, "capture backend: auto, opencv or v4l2")
+	input := flag.String("input", c.Input, "video file, image, image glob, testpattern:<name> or - to read Y4M/rgb24 frames from stdin")
+	loop := flag.Bool("loop", c.Loop, "Loop playback of the input")
+	interval := flag.Duration("interval", c.Interval, "time each still image is shown in a slideshow")
+	once := flag.Bool("once", c.Once, "Render a single frame to stdout and exit")
 	sample := flag.String("sample", c.SamplePath, "Where to find/store the sample data")
 	gen := flag.Bool("gen", c.GenerateSamples, "Generate a new background")
 	screen := flag.Bool("greenscreen", c.UseGreenscreen, "Use greenscreen")
 	screenDist := flag.Float64("threshold", c.Threshold, "Greenscreen threshold")
 	ansi := flag.Bool("ansi", c.ANSI, "Use ANSI")
+	blocks := flag.String("blocks", c.Blocks, "Block shape in ANSI mode: half, quadrant or sextant")
 	usecol := flag.String("color", c.Color, "Use single color")
 	w := flag.Uint("width", c.Width, "output width")
 	h := flag.Uint("height", c.Height, "output height")
 	camWidth := flag.Uint("camWidth", c.CamWidth, "cam input width")
 	camHeight := flag.Uint("camHeight", c.CamHeight, "cam input height")
-	zoom := flag.Uint("zoom", c.Zoom, "image zoom level (1-4, where 1=25%, 2=50%, 3=75%, 4=100%)")
+	mirror := flag.Bool("mirror", c.Mirror, "Mirror the image horizontally")
+	flip := flag.Bool("flip", c.Flip, "Flip the image vertically")
+	rotate := flag.Int("rotate", c.Rotate, "rotate the image clockwise by 0, 90, 180 or 270 degrees")
+	zoom := flag.Float64("zoom", c.Zoom, fmt.Sprintf("digital zoom magnification (1-%g)", MaxZoom))
+	center := flag.String("center", fmt.Sprintf("%g,%g", c.CenterX, c.CenterY), "center of the digital zoom as x,y fractions of the frame")
+	faceCascade := flag.String("faceCascade", c.FaceCascade, "OpenCV Haar cascade file to keep the digital zoom centered on a face")
+	shrink := flag.Uint("shrink", c.Shrink, "output size in quarters of the terminal (1-4, where 1=25%, 2=50%, 3=75%, 4=100%)")
+	scale := flag.String("scale", c.Scale, "how frames fit the terminal: fit, fill or stretch")
+	cellAspect := flag.Float64("cellAspect", c.CellAspect, "height to width ratio of terminal cells (0 detects it, falling back to 2)")
+	charset := flag.String("charset", c.Charset, fmt.Sprintf("character ramp: %s or %s<ramp>", strings.Join(ascii.CharsetNames(), ", "), ascii.CustomCharsetPrefix))
+	invertRamp := flag.Bool("invertRamp", c.InvertRamp, "Invert the character ramp for light terminal backgrounds")
+	shapes := flag.Bool("shapes", c.Shapes, "Pick glyphs matching the shapes in each cell, for crisper outlines")
+	edges := flag.Bool("edges", c.Edges, "Draw edges as lines over a faded image")
+	edgeThreshold := flag.Float64("edgeThreshold", c.EdgeThreshold, "Edge strength needed in edge mode, 1 being a step from black to white")
+	braille := flag.Bool("braille", c.Braille, "Use braille dots, for 2x4 pixels per character")
+	brailleDither := flag.Bool("brailleDither", c.BrailleDither, "Dither braille dots instead of using an adaptive threshold")
+	dither := flag.String("dither", c.Dither, "Dithering: none, bayer2, bayer4, bayer8, floyd-steinberg, atkinson or blue-noise")
+	adjustBrightness := flag.Float64("adjustBrightness", c.AdjustBrightness, "brighten (above 0) or darken (below 0) the image, from -1 to 1")
+	adjustContrast := flag.Float64("adjustContrast", c.AdjustContrast, fmt.Sprintf("image contrast factor (0-%g)", MaxContrast))
+	adjustGamma := flag.Float64("adjustGamma", c.AdjustGamma, fmt.Sprintf("image gamma, above 1 brightens midtones (%g-%g)", MinGamma, MaxGamma))
+	adjustSaturation := flag.Float64("adjustSaturation", c.AdjustSaturation, fmt.Sprintf("image saturation factor (0-%g)", MaxSaturation))
+	adjustHue := flag.Float64("adjustHue", c.AdjustHue, "rotate image hues by degrees")
+	autoLevels := flag.String("autoLevels", c.AutoLevels, "automatic levels per frame: none, stretch or equalize")
 	showFPS := flag.Bool("fps", c.ShowFPS, "Show FPS")
+	delta := flag.Bool("delta", c.Delta, "Only redraw the characters that changed since the previous frame")
+	deltaThreshold := flag.Float64("deltaThreshold", c.DeltaThreshold, "Color difference (CIELAB distance) below which characters are not redrawn")
+
+	// Camera properties
+	exposure := flag.Float64("exposure", 0, "camera exposure, in driver units (implies -autoExposure=false)")
+	gain := flag.Float64("gain", 0, "camera gain")
+	focus := flag.Float64("focus", 0, "camera focus, in driver units (implies -autoFocus=false)")
+	brightness := flag.Float64("brightness", 0, "camera brightness")
+	contrast := flag.Float64("contrast", 0, "camera contrast")
+	saturation := flag.Float64("saturation", 0, "camera saturation")
+	whiteBalance := flag.Float64("whiteBalance", 0, "camera white balance in Kelvin (implies -autoWhiteBalance=false)")
+	camFPS := flag.Float64("camFPS", 0, "camera frame rate")
+	autoExposure := flag.Bool("autoExposure", false, "camera auto exposure")
+	autoFocus := flag.Bool("autoFocus", false, "camera autofocus")
+	autoWhiteBalance := flag.Bool("autoWhiteBalance", false, "camera auto white balance")
 
 	flag.Parse()
 
+	// Only pass on camera properties that were actually set
+	camSize := 0
+	flag.Visit(func(f *flag.Flag) {
+		p := &c.Properties
+		switch f.Name {
+		case "camWidth", "camHeight":
+			camSize++
+		case "exposure":
+			p.Exposure = exposure
+		case "gain":
+			p.Gain = gain
+		case "focus":
+			p.Focus = focus
+		case "brightness":
+			p.Brightness = brightness
+		case "contrast":
+			p.Contrast = contrast
+		case "saturation":
+			p.Saturation = saturation
+		case "whiteBalance":
+			p.WhiteBalance = whiteBalance
+		case "camFPS":
+			p.FPS = camFPS
+		case "autoExposure":
+			p.AutoExposure = autoExposure
+		case "autoFocus":
+			p.AutoFocus = autoFocus
+		case "autoWhiteBalance":
+			p.AutoWhiteBalance = autoWhiteBalance
+		}
+	})
+
 	// Update config with parsed values
+	c.camSizeSet = camSize == 2
 	c.DeviceID = *deviceID
+	c.Backend = *backend
+	c.Input = *input
+	c.Loop = *loop
+	c.Interval = *interval
+	c.Once = *once
 	c.SamplePath = *sample
 	c.GenerateSamples = *gen
 	c.UseGreenscreen = *screen
 	c.Threshold = *screenDist
 	c.ANSI = *ansi
+	c.Blocks = *blocks
 	c.Color = *usecol
 	c.Width = *w
 	c.Height = *h
 	c.CamWidth = *camWidth
 	c.CamHeight = *camHeight
+	c.Mirror = *mirror
+	c.Flip = *flip
+	c.Rotate = *rotate
 	c.Zoom = *zoom
+	c.FaceCascade = *faceCascade
+	c.Shrink = *shrink
+	c.Scale = *scale
+	c.CellAspect = *cellAspect
 	c.ShowFPS = *showFPS
+	c.Delta = *delta
+	c.DeltaThreshold = *deltaThreshold
+	c.Charset = *charset
+	c.InvertRamp = *invertRamp
+	c.Shapes = *shapes
+	c.Edges = *edges
+	c.EdgeThreshold = *edgeThreshold
+	c.Braille = *braille
+	c.BrailleDither = *brailleDither
+	c.Dither = *dither
+	c.AdjustBrightness = *adjustBrightness
+	c.AdjustContrast = *adjustContrast
+	c.AdjustGamma = *adjustGamma
+	c.AdjustSaturation = *adjustSaturation
+	c.AdjustHue = *adjustHue
+	c.AutoLevels = *autoLevels
+
+	centerX, centerY, err := parseCenter(*center)
+	if err != nil {
+		return errors.NewConfigError("center", *center, err)
+	}
+	c.CenterX, c.CenterY = centerX, centerY
 
 	// Parse color if provided
 	if c.Color != "" {
@@ -105,40 +385,344 @@ func (c *Config) ParseFlags() error {
 
 // Validate validates the configuration and sets reasonable defaults.
 func (c *Config) Validate() error {
-	// Validate zoom level (1-4)
-	if c.Zoom < 1 {
-		c.Zoom = 1
-	} else if c.Zoom > 4 {
-		c.Zoom = 4
+	// Validate output size (1-4)
+	if c.Shrink < 1 {
+		c.Shrink = 1
+	} else if c.Shrink > 4 {
+		c.Shrink = 4
+	}
+
+	switch c.Backend {
+	case BackendAuto, BackendOpenCV, BackendV4L2:
+	default:
+		return errors.NewConfigError("backend", c.Backend, errors.ErrInvalidConfig)
+	}
+
+	// Make sure the input exists before trying to decode it
+	if err := c.validateInput(); err != nil {
+		return err
+	}
+
+	// Fail early on a camera that does not exist
+	if err := c.validateDevice(); err != nil {
+		return err
+	}
+
+	switch c.Rotate {
+	case 0, 90, 180, 270:
+	default:
+		return errors.NewConfigError("rotate", c.Rotate, errors.ErrInvalidConfig)
+	}
+
+	if c.Zoom < 1 || c.Zoom > MaxZoom {
+		return errors.NewConfigError("zoom", c.Zoom, errors.ErrInvalidConfig)
+	}
+	if c.CenterX < 0 || c.CenterX > 1 || c.CenterY < 0 || c.CenterY > 1 {
+		return errors.NewConfigError("center", fmt.Sprintf("%g,%g", c.CenterX, c.CenterY), errors.ErrInvalidConfig)
+	}
+
+	switch c.Scale {
+	case ScaleFit, ScaleFill, ScaleStretch:
+	default:
+		return errors.NewConfigError("scale", c.Scale, errors.ErrInvalidConfig)
+	}
+
+	if _, err := ascii.ParseCharset(c.Charset); err != nil {
+		return errors.NewConfigError("charset", c.Charset, err)
+	}
+	if _, err := ascii.ParseDitherMode(c.Dither); err != nil {
+		return errors.NewConfigError("dither", c.Dither, err)
+	}
+	if _, err := ascii.ParseBlockMode(c.Blocks); err != nil {
+		return errors.NewConfigError("blocks", c.Blocks, err)
+	}
+	if c.Blocks != string(ascii.BlocksHalf) && !c.ANSI {
+		return errors.NewConfigError("blocks", c.Blocks, fmt.Errorf("%w: block shapes need -ansi", errors.ErrInvalidConfig))
+	}
+
+	modes := c.renderModes()
+	if len(modes) > 1 {
+		return errors.NewConfigError(modes[1], true, fmt.Errorf("%w: cannot be combined with -%s", errors.ErrInvalidConfig, modes[0]))
+	}
+	if c.Edges && len(modes) > 0 {
+		return errors.NewConfigError("edges", c.Edges, fmt.Errorf("%w: cannot be combined with -%s", errors.ErrInvalidConfig, modes[0]))
+	}
+	if c.EdgeThreshold <= 0 {
+		return errors.NewConfigError("edgeThreshold", c.EdgeThreshold, errors.ErrInvalidConfig)
+	}
+
+	if c.DeltaThreshold < 0 {
+		return errors.NewConfigError("deltaThreshold", c.DeltaThreshold, errors.ErrInvalidConfig)
+	}
+
+	if err := c.validateAdjustments(); err != nil {
+		return err
+	}
+
+	if c.CellAspect < 0 {
+		return errors.NewConfigError("cellAspect", c.CellAspect, errors.ErrInvalidConfig)
+	}
+	if c.CellAspect == 0 {
+		c.autoCellAspect = true
+		c.CellAspect = detectedCellAspect()
+	}
+
+	if c.Interval <= 0 {
+		return errors.NewConfigError("interval", c.Interval, errors.ErrInvalidConfig)
+	}
+
+	if fps := c.Properties.FPS; fps != nil && *fps <= 0 {
+		return errors.NewConfigError("camFPS", *fps, errors.ErrInvalidConfig)
 	}
 
 	// Auto-detect terminal size if not explicitly set
-	if c.Width == 0 || c.Height == 0 {
+	c.autoWidth = c.Width == 0
+	c.autoHeight = c.Height == 0
+	c.Width, c.Height = c.displaySize(c.Width, c.Height)
+
+	return nil
+}
+
+// validateAdjustments checks that the image adjustments are within their
+// limits.
+func (c *Config) validateAdjustments() error {
+	if c.AdjustBrightness < -1 || c.AdjustBrightness > 1 {
+		return errors.NewConfigError("adjustBrightness", c.AdjustBrightness, errors.ErrInvalidConfig)
+	}
+	if c.AdjustContrast < 0 || c.AdjustContrast > MaxContrast {
+		return errors.NewConfigError("adjustContrast", c.AdjustContrast, errors.ErrInvalidConfig)
+	}
+	if c.AdjustGamma < MinGamma || c.AdjustGamma > MaxGamma {
+		return errors.NewConfigError("adjustGamma", c.AdjustGamma, errors.ErrInvalidConfig)
+	}
+	if c.AdjustSaturation < 0 || c.AdjustSaturation > MaxSaturation {
+		return errors.NewConfigError("adjustSaturation", c.AdjustSaturation, errors.ErrInvalidConfig)
+	}
+
+	switch c.AutoLevels {
+	case AutoLevelsNone, AutoLevelsStretch, AutoLevelsEqualize:
+	default:
+		return errors.NewConfigError("autoLevels", c.AutoLevels, errors.ErrInvalidConfig)
+	}
+	return nil
+}
+
+// displaySize fills in the terminal size for zero dimensions and returns the
+// display dimensions for width x height cells.
+func (c *Config) displaySize(width, height uint) (uint, uint) {
+	if width == 0 || height == 0 {
 		autoWidth, autoHeight := getTermSize()
-		if c.Width == 0 {
-			c.Width = autoWidth
+		if width == 0 {
+			width = autoWidth
 		}
-		if c.Height == 0 {
-			c.Height = autoHeight
+		if height == 0 {
+			height = autoHeight
 		}
 	}
 
 	// Set reasonable defaults if detection failed
-	if c.Width == 0 {
-		c.Width = 125
+	if width == 0 {
+		width = 125
+	}
+	if height == 0 {
+		height = 50
+	}
+
+	// Cells may show more than one pixel
+	cellWidth, cellHeight := c.CellSize()
+	return width * cellWidth, height * cellHeight
+}
+
+// UpdateTermSize detects the terminal size again after it was resized. Only
+// the settings that were detected rather than set explicitly change. It
+// reports whether the display dimensions changed.
+func (c *Config) UpdateTermSize() bool {
+	if c.autoCellAspect {
+		c.CellAspect = detectedCellAspect()
 	}
-	if c.Height == 0 {
-		c.Height = 50
+
+	if !c.autoWidth && !c.autoHeight {
+		return false
 	}
 
-	// ANSI rendering uses half-height blocks - adjust height
+	cellWidth, cellHeight := c.CellSize()
+	width, height := c.Width/cellWidth, c.Height/cellHeight
+	if c.autoWidth {
+		width = 0
+	}
+	if c.autoHeight {
+		height = 0
+	}
+
+	width, height = c.displaySize(width, height)
+	if width == c.Width && height == c.Height {
+		return false
+	}
+	c.Width, c.Height = width, height
+	return true
+}
+
+// renderModes returns the flags of the enabled render modes other than plain
+// ASCII. Only one of them can be used at a time.
+func (c *Config) renderModes() []string {
+	var modes []string
 	if c.ANSI {
-		c.Height *= 2
+		modes = append(modes, "ansi")
+	}
+	if c.Shapes {
+		modes = append(modes, "shapes")
+	}
+	if c.Braille {
+		modes = append(modes, "braille")
+	}
+	return modes
+}
+
+// CellSize returns how many pixels wide and tall each terminal cell is: ANSI
+// blocks hold a grid of pixels depending on their shape, shape matching
+// samples a grid and braille patterns hold a grid of dots.
+func (c *Config) CellSize() (uint, uint) {
+	switch {
+	case c.ANSI:
+		w, h := ascii.BlockMode(c.Blocks).CellSize()
+		return uint(w), uint(h)
+	case c.Shapes:
+		return ascii.ShapeCellWidth, ascii.ShapeCellHeight
+	case c.Braille:
+		return ascii.BrailleCellWidth, ascii.BrailleCellHeight
+	}
+	return 1, 1
+}
+
+// detectedCellAspect returns the cell aspect ratio reported by the terminal,
+// or the default if it does not report one.
+func detectedCellAspect() float64 {
+	if aspect, ok := detectCellAspect(); ok {
+		return aspect
+	}
+	return defaultCellAspect
+}
+
+// parseCenter parses a digital zoom center given as "x,y".
+func parseCenter(s string) (float64, float64, error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: want x,y", errors.ErrConfigParseFailed)
+	}
+
+	x, err := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", errors.ErrConfigParseFailed, err)
+	}
+	y, err := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", errors.ErrConfigParseFailed, err)
+	}
+
+	return x, y, nil
+}
+
+// Stdin returns standard input to read frames from. Validate may have read
+// ahead of os.Stdin, so frames need to be read from here instead.
+func (c *Config) Stdin() io.ReadCloser {
+	if c.stdin == nil {
+		c.stdin = bufio.NewReader(os.Stdin)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{c.stdin, os.Stdin}
+}
+
+// validateStdin checks that frames on stdin either start with a Y4M header,
+// which tells their size, or are rgb24 frames of an explicitly set size.
+func (c *Config) validateStdin() error {
+	if c.camSizeSet {
+		return nil
+	}
+
+	c.Stdin()
+	if magic, err := c.stdin.Peek(len(y4mMagic)); err == nil && string(magic) == y4mMagic {
+		return nil
+	}
+	return errors.NewConfigError("input", c.Input, fmt.Errorf("%w: rgb24 frames on stdin need -camWidth and -camHeight", errors.ErrInvalidConfig))
+}
+
+// validateInput checks that the input file, or at least one file matching
+// the input glob pattern, exists, that test patterns exist and that frames
+// on stdin have a known size.
+func (c *Config) validateInput() error {
+	if c.Input == StdinInput {
+		return c.validateStdin()
+	}
+	if name, ok := strings.CutPrefix(c.Input, TestPatternPrefix); ok {
+		if !slices.Contains(testPatterns, name) {
+			return errors.NewConfigError("input", c.Input, fmt.Errorf("%w: unknown test pattern %q (available: %s)", errors.ErrInvalidConfig, name, strings.Join(testPatterns, ", ")))
+		}
+		return nil
+	}
+	if c.Input == "" {
+		return nil
+	}
+
+	// A file whose name contains pattern characters is taken by its name
+	_, statErr := os.Stat(c.Input)
+	if statErr != nil && strings.ContainsAny(c.Input, "*?[") {
+		matches, err := filepath.Glob(c.Input)
+		if err != nil {
+			return errors.NewConfigError("input", c.Input, fmt.Errorf("%w: %v", errors.ErrInvalidConfig, err))
+		}
+		if len(matches) == 0 {
+			return errors.NewConfigError("input", c.Input, errors.ErrFileNotFound)
+		}
+		return nil
+	}
+
+	if statErr != nil {
+		return errors.NewConfigError("input", c.Input, fmt.Errorf("%w: %v", errors.ErrFileNotFound, statErr))
 	}
 
 	return nil
 }
 
+// videoDevicePath is the Linux device node of a camera, formatted with its
+// device ID. It is a variable so tests can point it at fake devices.
+var videoDevicePath = "/dev/video%d"
+
+// validateDevice checks that the camera device exists when no other input is
+// selected. Device nodes can only be checked on Linux; elsewhere OpenCV
+// reports missing cameras when opening them.
+func (c *Config) validateDevice() error {
+	if c.Input != "" {
+		return nil
+	}
+
+	if c.DeviceID < 0 {
+		return errors.NewConfigError("dev", c.DeviceID, errors.ErrInvalidConfig)
+	}
+
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+
+	path := fmt.Sprintf(videoDevicePath, c.DeviceID)
+	if _, err := os.Stat(path); err != nil {
+		return errors.NewConfigError("dev", c.DeviceID, fmt.Errorf("%w: %s does not exist, run 'asciicam devices' to list cameras", errors.ErrCameraNotFound, path))
+	}
+
+	return nil
+}
+
+// cellAspect returns the height to width ratio of the cells of a terminal
+// with the given size in cells and in pixels. It reports false if the pixel
+// size is unknown, which many terminals report as zero.
+func cellAspect(cols, rows, xPixels, yPixels uint16) (float64, bool) {
+	if cols == 0 || rows == 0 || xPixels == 0 || yPixels == 0 {
+		return 0, false
+	}
+	return (float64(yPixels) / float64(rows)) / (float64(xPixels) / float64(cols)), true
+}
+
 // getTermSize returns the current terminal dimensions.
 func getTermSize() (width, height uint) {
 	w, h := 0, 0
@@ -177,9 +761,16 @@ func (c *Config) GetCameraDimensions() (uint, uint) {
 	return c.CamWidth, c.CamHeight
 }
 
-// GetScaledDimensions returns the dimensions adjusted for zoom level.
+// PixelAspect returns the height to width ratio of the output pixels, which
+// depends on how many pixels each cell shows; see CellSize.
+func (c *Config) PixelAspect() float64 {
+	cellWidth, cellHeight := c.CellSize()
+	return c.CellAspect * float64(cellWidth) / float64(cellHeight)
+}
+
+// GetScaledDimensions returns the dimensions shrunk to the output size.
 func (c *Config) GetScaledDimensions() (uint, uint) {
-	scaleFactor := float64(c.Zoom) / 4.0 // Convert zoom 1-4 to 0.25-1.0 range
+	scaleFactor := float64(c.Shrink) / 4.0 // Convert quarters 1-4 to 0.25-1.0 range
 
 	scaledWidth := uint(float64(c.Width) * scaleFactor)
 	scaledHeight := uint(float64(c.Height) * scaleFactor)
@@ -1,12 +1,41 @@
 package config
 
 import (
+	"bufio"
+	stderrors "errors"
 	"flag"
 	"image/color"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/muesli/asciicam/internal/ascii"
+	"github.com/muesli/asciicam/internal/errors"
 )
 
+// TestMain points the camera device check at fake /dev/video0 and
+// /dev/video1 nodes, so tests validating camera configurations pass on
+// machines without cameras.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "asciicam-dev")
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range []string{"video0", "video1"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			panic(err)
+		}
+	}
+	videoDevicePath = filepath.Join(dir, "video%d")
+
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestNewConfig(t *testing.T) {
 	cfg := NewConfig()
 
@@ -27,8 +56,16 @@ func TestNewConfig(t *testing.T) {
 		t.Errorf("Expected default CamHeight 1080, got %d", cfg.CamHeight)
 	}
 
-	if cfg.Zoom != 4 {
-		t.Errorf("Expected default Zoom 4, got %d", cfg.Zoom)
+	if cfg.Shrink != 4 {
+		t.Errorf("Expected default Shrink 4, got %d", cfg.Shrink)
+	}
+
+	if cfg.Zoom != 1 {
+		t.Errorf("Expected default Zoom 1, got %g", cfg.Zoom)
+	}
+
+	if cfg.Scale != ScaleFit {
+		t.Errorf("Expected default Scale %s, got %s", ScaleFit, cfg.Scale)
 	}
 
 	if cfg.ANSI != false {
@@ -125,23 +162,23 @@ func TestParseFlags_InvalidColor(t *testing.T) {
 func TestValidate(t *testing.T) {
 	cfg := NewConfig()
 
-	// Test zoom validation
-	cfg.Zoom = 0 // Invalid
+	// Test output size validation
+	cfg.Shrink = 0 // Invalid
 	err := cfg.Validate()
 	if err != nil {
 		t.Errorf("Validate() returned error: %v", err)
 	}
-	if cfg.Zoom != 1 {
-		t.Errorf("Expected Zoom to be corrected to 1, got %d", cfg.Zoom)
+	if cfg.Shrink != 1 {
+		t.Errorf("Expected Shrink to be corrected to 1, got %d", cfg.Shrink)
 	}
 
-	cfg.Zoom = 10 // Invalid (too high)
+	cfg.Shrink = 10 // Invalid (too high)
 	err = cfg.Validate()
 	if err != nil {
 		t.Errorf("Validate() returned error: %v", err)
 	}
-	if cfg.Zoom != 4 {
-		t.Errorf("Expected Zoom to be corrected to 4, got %d", cfg.Zoom)
+	if cfg.Shrink != 4 {
+		t.Errorf("Expected Shrink to be corrected to 4, got %d", cfg.Shrink)
 	}
 }
 
@@ -161,6 +198,615 @@ func TestValidate_ANSI(t *testing.T) {
 	}
 }
 
+func TestValidate_Shapes(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Shapes = true
+	cfg.Width, cfg.Height = 80, 24
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+
+	// Each cell is sampled as a grid of pixels
+	if cfg.Width != 80*3 || cfg.Height != 24*6 {
+		t.Errorf("Expected 240x144 pixels for shape matching, got %dx%d", cfg.Width, cfg.Height)
+	}
+
+	cfg = NewConfig()
+	cfg.Shapes = true
+	cfg.ANSI = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for shape matching in ANSI mode")
+	}
+}
+
+func TestValidate_Blocks(t *testing.T) {
+	tests := []struct {
+		blocks       string
+		wantW, wantH uint
+	}{
+		{"half", 80, 48},
+		{"quadrant", 160, 48},
+		{"sextant", 160, 72},
+	}
+
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.ANSI = true
+		cfg.Blocks = tt.blocks
+		cfg.Width, cfg.Height = 80, 24
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate() returned error for %s blocks: %v", tt.blocks, err)
+		}
+		if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+			t.Errorf("Expected %dx%d pixels for %s blocks, got %dx%d", tt.wantW, tt.wantH, tt.blocks, cfg.Width, cfg.Height)
+		}
+
+		// The terminal size is recovered from the pixel size
+		cfg.autoWidth, cfg.autoHeight = false, false
+		if cfg.UpdateTermSize() {
+			t.Errorf("Expected no change for fixed size with %s blocks", tt.blocks)
+		}
+	}
+
+	cfg := NewConfig()
+	cfg.Blocks = "quadrant"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for block shape without ANSI mode")
+	}
+
+	cfg = NewConfig()
+	cfg.ANSI = true
+	cfg.Blocks = "octant"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown block shape")
+	}
+}
+
+func TestValidate_Braille(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Braille = true
+	cfg.Width, cfg.Height = 80, 24
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+
+	// Each cell holds 2x4 dots
+	if cfg.Width != 160 || cfg.Height != 96 {
+		t.Errorf("Expected 160x96 pixels for braille, got %dx%d", cfg.Width, cfg.Height)
+	}
+	if got := cfg.PixelAspect(); got != 1 {
+		t.Errorf("Expected square braille dots, got pixel aspect %g", got)
+	}
+
+	for _, other := range []func(*Config){
+		func(c *Config) { c.ANSI = true },
+		func(c *Config) { c.Shapes = true },
+		func(c *Config) { c.Edges = true },
+	} {
+		cfg := NewConfig()
+		cfg.Braille = true
+		other(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Error("Expected error for braille combined with another mode")
+		}
+	}
+}
+
+func TestValidate_Dither(t *testing.T) {
+	for _, mode := range ascii.DitherModes {
+		cfg := NewConfig()
+		cfg.Dither = string(mode)
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() returned error for %s dithering: %v", mode, err)
+		}
+	}
+
+	cfg := NewConfig()
+	cfg.Dither = "random"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown dither mode")
+	}
+}
+
+func TestValidate_Edges(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Edges = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"ansi", func(c *Config) { c.ANSI = true }},
+		{"shapes", func(c *Config) { c.Shapes = true }},
+		{"zero threshold", func(c *Config) { c.EdgeThreshold = 0 }},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.Edges = true
+		tt.modify(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected error for edges with %s", tt.name)
+		}
+	}
+}
+
+func TestParseFlags_Input(t *testing.T) {
+	// Reset flag package for clean testing
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	input := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(input, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Args = []string{"test", "-input=" + input, "-loop=true"}
+
+	cfg := NewConfig()
+	if err := cfg.ParseFlags(); err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+
+	if cfg.Input != input {
+		t.Errorf("Expected Input %s, got %s", input, cfg.Input)
+	}
+
+	if !cfg.Loop {
+		t.Error("Expected Loop true")
+	}
+}
+
+func TestParseFlags_CameraProperties(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"test", "-exposure=200", "-autoFocus=false", "-camFPS=15"}
+
+	cfg := NewConfig()
+	if err := cfg.ParseFlags(); err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+
+	p := cfg.Properties
+	if p.Exposure == nil || *p.Exposure != 200 {
+		t.Errorf("Expected exposure 200, got %v", p.Exposure)
+	}
+	if p.AutoFocus == nil || *p.AutoFocus {
+		t.Errorf("Expected autofocus set to false, got %v", p.AutoFocus)
+	}
+	if p.FPS == nil || *p.FPS != 15 {
+		t.Errorf("Expected camera FPS 15, got %v", p.FPS)
+	}
+
+	// Properties not on the command line stay unset
+	if p.Gain != nil || p.AutoExposure != nil || p.Brightness != nil {
+		t.Error("Expected unset camera properties to be nil")
+	}
+}
+
+func TestValidate_CameraFPS(t *testing.T) {
+	cfg := NewConfig()
+	fps := 0.0
+	cfg.Properties.FPS = &fps
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for zero camera FPS, got none")
+	}
+}
+
+func TestValidate_InputNotFound(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Input = "does-not-exist.mp4"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected error for missing input file, got none")
+	}
+
+	if _, ok := err.(*errors.ConfigError); !ok {
+		t.Errorf("Expected *errors.ConfigError, got %T", err)
+	}
+}
+
+func TestValidate_DeviceNotFound(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("device nodes are only checked on Linux")
+	}
+
+	cfg := NewConfig()
+	cfg.DeviceID = 7
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected error for missing camera device, got none")
+	}
+
+	if _, ok := err.(*errors.ConfigError); !ok {
+		t.Errorf("Expected *errors.ConfigError, got %T", err)
+	}
+
+	if !stderrors.Is(err, errors.ErrCameraNotFound) {
+		t.Errorf("Expected error to wrap ErrCameraNotFound, got %v", err)
+	}
+
+	// Other inputs do not need a camera
+	cfg.Input = TestPatternPrefix + "bars"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() returned error for test pattern input: %v", err)
+	}
+}
+
+func TestValidate_NegativeDevice(t *testing.T) {
+	cfg := NewConfig()
+	cfg.DeviceID = -1
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative device ID, got none")
+	}
+}
+
+func TestValidate_InputGlob(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "1.png"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.Input = filepath.Join(dir, "*.png")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() returned error for matching glob: %v", err)
+	}
+
+	cfg.Input = filepath.Join(dir, "*.jpg")
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for glob without matches, got none")
+	}
+
+	// A file named like a pattern is taken by its name
+	cfg.Input = filepath.Join(dir, "clip[1].mp4")
+	if err := os.WriteFile(cfg.Input, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() returned error for file with brackets: %v", err)
+	}
+}
+
+func TestValidate_InputStdin(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     string
+		camSizeSet bool
+		wantErr    bool
+	}{
+		{"y4m", "YUV4MPEG2 W2 H2 F30:1\n", false, false},
+		{"rgb24 with size", "\x00\x00\x00", true, false},
+		{"rgb24 without size", "\x00\x00\x00", false, true},
+		{"empty", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.Input = StdinInput
+			cfg.camSizeSet = tt.camSizeSet
+			cfg.stdin = bufio.NewReader(strings.NewReader(tt.stream))
+
+			err := cfg.Validate()
+			if tt.wantErr && !stderrors.Is(err, errors.ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() returned error for stdin input: %v", err)
+			}
+
+			// The header is still there to be read
+			if got, _ := io.ReadAll(cfg.stdin); string(got) != tt.stream {
+				t.Errorf("Expected the stream to stay unread, got %q", got)
+			}
+		})
+	}
+}
+
+func TestParseFlags_CamSize(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"test", "-camWidth=320", "-camHeight=240"}, true},
+		{[]string{"test", "-camWidth=320"}, false},
+		{[]string{"test"}, false},
+	} {
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		os.Args = tt.args
+
+		cfg := NewConfig()
+		if err := cfg.ParseFlags(); err != nil {
+			t.Fatalf("ParseFlags() returned error: %v", err)
+		}
+		if cfg.camSizeSet != tt.want {
+			t.Errorf("Expected camera size set %v for %v, got %v", tt.want, tt.args[1:], cfg.camSizeSet)
+		}
+	}
+}
+
+func TestValidate_InputTestPattern(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Input = TestPatternPrefix + "bars"
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() returned error for test pattern input: %v", err)
+	}
+
+	cfg.Input = TestPatternPrefix + "bar"
+	if err := cfg.Validate(); !stderrors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for unknown test pattern, got %v", err)
+	}
+}
+
+func TestValidate_Backend(t *testing.T) {
+	for _, backend := range []string{BackendAuto, BackendOpenCV, BackendV4L2} {
+		cfg := NewConfig()
+		cfg.Backend = backend
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() returned error for backend %s: %v", backend, err)
+		}
+	}
+
+	cfg := NewConfig()
+	cfg.Backend = "directshow"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown backend, got none")
+	}
+}
+
+func TestParseFlags_Orientation(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"test", "-mirror", "-flip", "-rotate=90"}
+
+	cfg := NewConfig()
+	if err := cfg.ParseFlags(); err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+
+	if !cfg.Mirror || !cfg.Flip || cfg.Rotate != 90 {
+		t.Errorf("Expected mirror, flip and 90 degree rotation, got %v, %v, %d", cfg.Mirror, cfg.Flip, cfg.Rotate)
+	}
+}
+
+func TestValidate_Rotate(t *testing.T) {
+	for _, rotate := range []int{0, 90, 180, 270} {
+		cfg := NewConfig()
+		cfg.Rotate = rotate
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() returned error for rotation %d: %v", rotate, err)
+		}
+	}
+
+	for _, rotate := range []int{45, -90, 360} {
+		cfg := NewConfig()
+		cfg.Rotate = rotate
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected error for rotation %d, got none", rotate)
+		}
+	}
+}
+
+func TestParseFlags_DigitalZoom(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"test", "-zoom=2.5", "-center=0.25, 0.75"}
+
+	cfg := NewConfig()
+	if err := cfg.ParseFlags(); err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+
+	if cfg.Zoom != 2.5 {
+		t.Errorf("Expected magnification 2.5, got %g", cfg.Zoom)
+	}
+	if cfg.CenterX != 0.25 || cfg.CenterY != 0.75 {
+		t.Errorf("Expected center 0.25,0.75, got %g,%g", cfg.CenterX, cfg.CenterY)
+	}
+}
+
+func TestParseFlags_InvalidCenter(t *testing.T) {
+	for _, center := range []string{"0.5", "x,0.5", "0.5,y"} {
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		os.Args = []string{"test", "-center=" + center}
+
+		cfg := NewConfig()
+		if err := cfg.ParseFlags(); err == nil {
+			t.Errorf("Expected error for center %q, got none", center)
+		}
+	}
+}
+
+func TestValidate_DigitalZoom(t *testing.T) {
+	tests := []struct {
+		name             string
+		zoom             float64
+		centerX, centerY float64
+		wantErr          bool
+	}{
+		{"defaults", 1, 0.5, 0.5, false},
+		{"strongest", MaxZoom, 0, 1, false},
+		{"below one", 0.5, 0.5, 0.5, true},
+		{"too strong", MaxZoom * 2, 0.5, 0.5, true},
+		{"center outside", 2, 1.5, 0.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.Zoom = tt.zoom
+			cfg.CenterX, cfg.CenterY = tt.centerX, tt.centerY
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Scale(t *testing.T) {
+	for _, scale := range []string{ScaleFit, ScaleFill, ScaleStretch} {
+		cfg := NewConfig()
+		cfg.Scale = scale
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() returned error for scale %s: %v", scale, err)
+		}
+	}
+
+	cfg := NewConfig()
+	cfg.Scale = "zoom"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown scale mode, got none")
+	}
+}
+
+func TestValidate_CellAspect(t *testing.T) {
+	cfg := NewConfig()
+	cfg.CellAspect = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative cell aspect, got none")
+	}
+
+	// Zero is detected, or falls back to the default
+	cfg = NewConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+	if cfg.CellAspect <= 0 {
+		t.Errorf("Expected detected cell aspect, got %g", cfg.CellAspect)
+	}
+}
+
+func TestCellAspect(t *testing.T) {
+	tests := []struct {
+		name                   string
+		cols, rows, xPix, yPix uint16
+		want                   float64
+		wantOK                 bool
+	}{
+		{"typical", 80, 24, 640, 384, 2, true},
+		{"square cells", 100, 50, 800, 400, 1, true},
+		{"no pixel size", 80, 24, 0, 0, 0, false},
+		{"no cells", 0, 0, 640, 384, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cellAspect(tt.cols, tt.rows, tt.xPix, tt.yPix)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("cellAspect() = %g, %v, want %g, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPixelAspect(t *testing.T) {
+	cfg := NewConfig()
+	cfg.CellAspect = 2.2
+	if got := cfg.PixelAspect(); got != 2.2 {
+		t.Errorf("Expected pixel aspect 2.2 in ASCII mode, got %g", got)
+	}
+
+	// Half blocks put two pixels in each cell
+	cfg.ANSI = true
+	if got := cfg.PixelAspect(); got != 1.1 {
+		t.Errorf("Expected pixel aspect 1.1 in ANSI mode, got %g", got)
+	}
+
+	// Shape matching samples 3x6 pixels per cell
+	cfg.ANSI = false
+	cfg.Shapes = true
+	if got := cfg.PixelAspect(); got != 1.1 {
+		t.Errorf("Expected pixel aspect 1.1 for shape matching, got %g", got)
+	}
+}
+
+func TestUpdateTermSize(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ANSI = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+	width, height := cfg.GetDisplayDimensions()
+
+	// Nothing changed since the size was detected
+	if cfg.UpdateTermSize() {
+		t.Error("Expected no change without a resize")
+	}
+
+	// The terminal had another size before
+	cfg.Width, cfg.Height = width+10, height+10
+	if !cfg.UpdateTermSize() {
+		t.Fatal("Expected the size to change")
+	}
+	if w, h := cfg.GetDisplayDimensions(); w != width || h != height {
+		t.Errorf("Expected %dx%d after update, got %dx%d", width, height, w, h)
+	}
+}
+
+func TestUpdateTermSize_Explicit(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Width = 100
+	cfg.Height = 40
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+
+	if cfg.UpdateTermSize() {
+		t.Error("Expected explicit dimensions to stay")
+	}
+	if w, h := cfg.GetDisplayDimensions(); w != 100 || h != 40 {
+		t.Errorf("Expected 100x40, got %dx%d", w, h)
+	}
+}
+
+func TestParseFlags_Charset(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"test", "-charset=blocks", "-invertRamp"}
+
+	cfg := NewConfig()
+	if err := cfg.ParseFlags(); err != nil {
+		t.Fatalf("ParseFlags() returned error: %v", err)
+	}
+
+	if cfg.Charset != "blocks" || !cfg.InvertRamp {
+		t.Errorf("Expected inverted blocks charset, got %q, %v", cfg.Charset, cfg.InvertRamp)
+	}
+}
+
+func TestValidate_Charset(t *testing.T) {
+	for _, charset := range []string{"standard", "binary", "custom: .oO@"} {
+		cfg := NewConfig()
+		cfg.Charset = charset
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() returned error for charset %q: %v", charset, err)
+		}
+	}
+
+	for _, charset := range []string{"fancy", "custom:#", ""} {
+		cfg := NewConfig()
+		cfg.Charset = charset
+		err := cfg.Validate()
+		if !stderrors.Is(err, errors.ErrInvalidCharset) {
+			t.Errorf("Expected ErrInvalidCharset for charset %q, got %v", charset, err)
+		}
+	}
+}
+
+func TestValidate_Interval(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Interval = 0
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for zero interval, got none")
+	}
+}
+
 func TestGetCameraDimensions(t *testing.T) {
 	cfg := NewConfig()
 	cfg.CamWidth = 1280
@@ -189,7 +835,7 @@ func TestGetScaledDimensions(t *testing.T) {
 	cfg.Height = 24
 
 	tests := []struct {
-		zoom      uint
+		shrink    uint
 		expectedW uint
 		expectedH uint
 	}{
@@ -200,13 +846,13 @@ func TestGetScaledDimensions(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		t.Run("zoom_"+string(rune(tt.zoom+'0')), func(t *testing.T) {
-			cfg.Zoom = tt.zoom
+		t.Run("shrink_"+string(rune(tt.shrink+'0')), func(t *testing.T) {
+			cfg.Shrink = tt.shrink
 			w, h := cfg.GetScaledDimensions()
 
 			if w != tt.expectedW || h != tt.expectedH {
-				t.Errorf("For zoom %d, expected %dx%d, got %dx%d",
-					tt.zoom, tt.expectedW, tt.expectedH, w, h)
+				t.Errorf("For shrink %d, expected %dx%d, got %dx%d",
+					tt.shrink, tt.expectedW, tt.expectedH, w, h)
 			}
 		})
 	}
@@ -216,7 +862,7 @@ func TestGetScaledDimensions_NoOverflow(t *testing.T) {
 	cfg := NewConfig()
 	cfg.Width = 1000
 	cfg.Height = 1000
-	cfg.Zoom = 4
+	cfg.Shrink = 4
 
 	w, h := cfg.GetScaledDimensions()
 
@@ -300,7 +946,7 @@ func BenchmarkGetScaledDimensions(b *testing.B) {
 	cfg := NewConfig()
 	cfg.Width = 1920
 	cfg.Height = 1080
-	cfg.Zoom = 2
+	cfg.Shrink = 2
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -308,3 +954,52 @@ func BenchmarkGetScaledDimensions(b *testing.B) {
 		_, _ = w, h // Prevent optimization
 	}
 }
+
+func TestValidate_Adjustments(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"all set", func(c *Config) {
+			c.AdjustBrightness, c.AdjustContrast, c.AdjustGamma = 0.2, 1.5, 1.8
+			c.AdjustSaturation, c.AdjustHue, c.AutoLevels = 0, -45, AutoLevelsEqualize
+		}, false},
+		{"brightness", func(c *Config) { c.AdjustBrightness = 1.5 }, true},
+		{"contrast", func(c *Config) { c.AdjustContrast = -1 }, true},
+		{"gamma", func(c *Config) { c.AdjustGamma = 0 }, true},
+		{"saturation", func(c *Config) { c.AdjustSaturation = MaxSaturation + 1 }, true},
+		{"auto levels", func(c *Config) { c.AutoLevels = "auto" }, true},
+	}
+
+	for _, tt := range tests {
+		cfg := NewConfig()
+		tt.set(cfg)
+		err := cfg.Validate()
+		if tt.wantErr && !stderrors.Is(err, errors.ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", tt.name, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: Validate() returned error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidate_DeltaThreshold(t *testing.T) {
+	cfg := NewConfig()
+	if !cfg.Delta {
+		t.Error("Expected delta rendering by default")
+	}
+
+	cfg.DeltaThreshold = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() returned error for threshold 0: %v", err)
+	}
+
+	cfg = NewConfig()
+	cfg.DeltaThreshold = -0.1
+	if err := cfg.Validate(); !stderrors.Is(err, errors.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for negative threshold, got %v", err)
+	}
+}
@@ -13,12 +13,18 @@ var (
 	ErrCameraInitFailed  = errors.New("failed to initialize camera")
 	ErrCameraReadFailed  = errors.New("failed to read frame from camera")
 	ErrCameraUnsupported = errors.New("camera device not supported")
+	ErrCameraLost        = errors.New("camera disconnected")
+
+	// Input errors
+	ErrEndOfStream = errors.New("end of input stream")
 
 	// Configuration errors
 	ErrInvalidConfig     = errors.New("invalid configuration")
 	ErrConfigParseFailed = errors.New("failed to parse configuration")
 	ErrInvalidColorCode  = errors.New("invalid color code")
 	ErrInvalidDimensions = errors.New("invalid dimensions")
+	ErrInvalidCharset    = errors.New("invalid character ramp")
+	ErrInvalidFont       = errors.New("invalid font")
 
 	// File operation errors
 	ErrFileNotFound    = errors.New("file not found")
@@ -31,6 +37,7 @@ var (
 	ErrImageResizeFailed  = errors.New("failed to resize image")
 	ErrImageDecodeFailed  = errors.New("failed to decode image")
 	ErrImageEncodeFailed  = errors.New("failed to encode image")
+	ErrUnsupportedFormat  = errors.New("unsupported pixel format")
 
 	// Greenscreen errors
 	ErrGreenscreenLoadFailed  = errors.New("failed to load greenscreen background")
@@ -152,6 +159,8 @@ func IsRetryable(err error) bool {
 		return true
 	case errors.Is(err, ErrImageProcessFailed):
 		return true
+	case errors.Is(err, ErrCameraLost):
+		return true
 	default:
 		return false
 	}
@@ -177,3 +186,15 @@ func IsFatal(err error) bool {
 		return false
 	}
 }
+
+// IsCameraLost determines if an error signals that the camera went away and
+// is being reconnected
+func IsCameraLost(err error) bool {
+	return errors.Is(err, ErrCameraLost)
+}
+
+// IsEndOfStream determines if an error signals that a finite input source
+// (such as a video file) has no more frames to deliver
+func IsEndOfStream(err error) bool {
+	return errors.Is(err, ErrEndOfStream)
+}
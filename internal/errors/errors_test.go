@@ -71,6 +71,7 @@ func TestIsRetryable(t *testing.T) {
 		{"camera read failed", ErrCameraReadFailed, true},
 		{"file read failed", ErrFileReadFailed, true},
 		{"image process failed", ErrImageProcessFailed, true},
+		{"camera lost", ErrCameraLost, true},
 		{"camera not found", ErrCameraNotFound, false},
 		{"other error", errors.New("other"), false},
 	}
@@ -108,6 +109,40 @@ func TestIsFatal(t *testing.T) {
 	}
 }
 
+func TestIsEndOfStream(t *testing.T) {
+	if IsEndOfStream(nil) {
+		t.Error("IsEndOfStream(nil) should be false")
+	}
+
+	if !IsEndOfStream(ErrEndOfStream) {
+		t.Error("IsEndOfStream(ErrEndOfStream) should be true")
+	}
+
+	wrapped := NewFileError("clip.mp4", "read", ErrEndOfStream)
+	if !IsEndOfStream(wrapped) {
+		t.Error("IsEndOfStream should detect wrapped end of stream")
+	}
+
+	if IsEndOfStream(ErrCameraReadFailed) {
+		t.Error("IsEndOfStream(ErrCameraReadFailed) should be false")
+	}
+}
+
+func TestIsCameraLost(t *testing.T) {
+	if IsCameraLost(nil) {
+		t.Error("IsCameraLost(nil) should be false")
+	}
+
+	wrapped := NewCameraError(0, "reconnect", ErrCameraLost)
+	if !IsCameraLost(wrapped) {
+		t.Error("IsCameraLost should detect wrapped camera loss")
+	}
+
+	if IsCameraLost(ErrCameraReadFailed) {
+		t.Error("IsCameraLost(ErrCameraReadFailed) should be false")
+	}
+}
+
 func TestErrorUnwrapping(t *testing.T) {
 	originalErr := errors.New("root cause")
 	cameraErr := NewCameraError(1, "test", originalErr)
@@ -18,7 +18,8 @@ import (
 type Processor struct {
 	samplePath string
 	threshold  float64
-	background image.Image
+	sample     image.Image // background sample as loaded
+	background image.Image // sample resized to the output
 }
 
 // NewProcessor creates a new greenscreen processor.
@@ -40,12 +41,29 @@ func (p *Processor) LoadBackgroundWithContext(ctx context.Context, width, height
 		return fmt.Errorf("context cancelled: %w", err)
 	}
 
-	bg, err := p.loadBgSamples(width, height)
+	sample, err := p.loadBgSamples()
 	if err != nil {
 		return fmt.Errorf("%w: %v", errors.ErrGreenscreenLoadFailed, err)
 	}
 
-	p.background = bg
+	p.sample = sample
+	return p.Resize(width, height)
+}
+
+// Resize rescales the loaded background to new output dimensions, without
+// loading it again. It does nothing if no background has been loaded.
+func (p *Processor) Resize(width, height uint) error {
+	if p.sample == nil {
+		return nil
+	}
+
+	// Resize the background image to match the terminal dimensions
+	resized := resize.Resize(width, height, p.sample, resize.Bilinear)
+	if resized == nil {
+		return fmt.Errorf("%w: %v", errors.ErrGreenscreenLoadFailed, errors.NewImageError("resize", fmt.Sprintf("%dx%d", width, height), errors.ErrImageResizeFailed))
+	}
+
+	p.background = resized
 	return nil
 }
 
@@ -109,7 +127,7 @@ func (p *Processor) GenerateSamplesWithContext(ctx context.Context, img image.Im
 
 // loadBgSamples loads a background sample image for use with the greenscreen effect.
 // Currently, it loads a single sample image, but could be extended to average multiple samples.
-func (p *Processor) loadBgSamples(width, height uint) (image.Image, error) {
+func (p *Processor) loadBgSamples() (image.Image, error) {
 	// TODO: take average of sample set
 	// Currently only using a single sample
 	i := 40
@@ -125,13 +143,7 @@ func (p *Processor) loadBgSamples(width, height uint) (image.Image, error) {
 		return nil, errors.NewFileError(filename, "decode", fmt.Errorf("%w: %v", errors.ErrImageDecodeFailed, err))
 	}
 
-	// Resize the background image to match the terminal dimensions
-	resized := resize.Resize(width, height, img, resize.Bilinear)
-	if resized == nil {
-		return nil, errors.NewImageError("resize", fmt.Sprintf("%dx%d", width, height), errors.ErrImageResizeFailed)
-	}
-
-	return resized, nil
+	return img, nil
 }
 
 // GetThreshold returns the current threshold value.
@@ -223,6 +223,34 @@ func TestLoadBackground_WithValidFile(t *testing.T) {
 	}
 }
 
+func TestResize(t *testing.T) {
+	tempDir := t.TempDir()
+	processor := NewProcessor(tempDir, 0.1)
+
+	// Without a background there is nothing to resize
+	if err := processor.Resize(10, 10); err != nil || processor.HasBackground() {
+		t.Fatalf("Expected Resize() to do nothing without a background, got %v", err)
+	}
+
+	if err := processor.GenerateSamples(image.NewRGBA(image.Rect(0, 0, 20, 20)), 40); err != nil {
+		t.Fatalf("Failed to generate sample: %v", err)
+	}
+	if err := processor.LoadBackground(20, 20); err != nil {
+		t.Fatalf("LoadBackground() returned error: %v", err)
+	}
+
+	// The sample is rescaled without reading it again
+	if err := os.Remove(filepath.Join(tempDir, "40.png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.Resize(30, 15); err != nil {
+		t.Fatalf("Resize() returned error: %v", err)
+	}
+	if b := processor.background.Bounds(); b.Dx() != 30 || b.Dy() != 15 {
+		t.Errorf("Expected 30x15 background, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
 // Benchmark tests
 func BenchmarkApply(b *testing.B) {
 	processor := NewProcessor("test", 0.1)
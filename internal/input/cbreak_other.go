@@ -0,0 +1,10 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package input
+
+import "github.com/muesli/asciicam/internal/errors"
+
+// enableCbreak is not supported on this platform.
+func enableCbreak(int) (func() error, error) {
+	return nil, errors.ErrTerminalNotTTY
+}
@@ -0,0 +1,163 @@
+// Package input provides keyboard handling for interactive playback controls.
+package input
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/muesli/asciicam/internal/errors"
+	"golang.org/x/term"
+)
+
+// Key is a single key press. Printable keys are represented by their rune,
+// special keys by the negative constants below.
+type Key rune
+
+// Special keys.
+const (
+	KeyUp Key = -(iota + 1)
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyEscape
+)
+
+// Keyboard reads key presses from a terminal without waiting for Enter.
+// Output processing and signal generation are left enabled, so Ctrl+C still
+// raises SIGINT and newlines still render as usual.
+type Keyboard struct {
+	restore func() error
+	keys    chan Key
+}
+
+// NewKeyboard switches the terminal attached to f into cbreak mode and starts
+// delivering key presses. It returns errors.ErrTerminalNotTTY if f is not a
+// terminal. Call Close to restore the terminal.
+func NewKeyboard(f *os.File) (*Keyboard, error) {
+	fd := int(f.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.ErrTerminalNotTTY
+	}
+
+	restore, err := enableCbreak(fd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrTerminalNotTTY, err)
+	}
+
+	k := &Keyboard{
+		restore: restore,
+		keys:    make(chan Key, 16),
+	}
+	go k.read(f)
+
+	return k, nil
+}
+
+// Keys returns the channel key presses are delivered on.
+func (k *Keyboard) Keys() <-chan Key {
+	return k.keys
+}
+
+// Close restores the terminal to its previous state.
+// The reader goroutine stays blocked on the terminal until the process exits.
+func (k *Keyboard) Close() error {
+	return k.restore()
+}
+
+// escapeTimeout is how long a read ending in part of an escape sequence
+// waits for the rest of it, which may arrive in a later read over slow
+// connections, before it counts as a press of Escape.
+const escapeTimeout = 100 * time.Millisecond
+
+// read forwards decoded key presses until reading from f fails.
+// Keys are dropped rather than blocking if nobody is consuming them.
+func (k *Keyboard) read(f *os.File) {
+	chunks := make(chan []byte)
+	go func() {
+		defer close(chunks)
+		for {
+			buf := make([]byte, 64)
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			chunks <- buf[:n]
+		}
+	}()
+
+	var pending []byte
+	var timeout <-chan time.Time
+	for {
+		var keys []Key
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return
+			}
+			keys, pending = parseKeys(append(pending, chunk...), false)
+			timeout = nil
+			if len(pending) > 0 {
+				timeout = time.After(escapeTimeout)
+			}
+		case <-timeout:
+			keys, pending, timeout = ParseKeys(pending), nil, nil
+		}
+
+		for _, key := range keys {
+			select {
+			case k.keys <- key:
+			default:
+			}
+		}
+	}
+}
+
+// ParseKeys decodes the bytes of a terminal read into key presses,
+// recognizing ANSI escape sequences for the arrow keys.
+func ParseKeys(b []byte) []Key {
+	keys, _ := parseKeys(b, true)
+	return keys
+}
+
+// parseKeys decodes b into key presses. Unless final is set, an escape
+// sequence cut off at the end of b is returned undecoded, to be completed
+// by the next read.
+func parseKeys(b []byte, final bool) ([]Key, []byte) {
+	var keys []Key
+	for i := 0; i < len(b); i++ {
+		if b[i] != 0x1b {
+			keys = append(keys, Key(b[i]))
+			continue
+		}
+
+		// ESC, or ESC [ or ESC O, may be the start of an arrow key
+		if !final && (i+1 == len(b) || i+2 == len(b) && (b[i+1] == '[' || b[i+1] == 'O')) {
+			return keys, b[i:]
+		}
+
+		// CSI (ESC [) and SS3 (ESC O) arrow key sequences
+		if i+2 < len(b) && (b[i+1] == '[' || b[i+1] == 'O') {
+			var key Key
+			switch b[i+2] {
+			case 'A':
+				key = KeyUp
+			case 'B':
+				key = KeyDown
+			case 'C':
+				key = KeyRight
+			case 'D':
+				key = KeyLeft
+			}
+			if key != 0 {
+				keys = append(keys, key)
+				i += 2
+				continue
+			}
+		}
+
+		keys = append(keys, KeyEscape)
+	}
+
+	return keys, nil
+}
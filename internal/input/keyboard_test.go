@@ -0,0 +1,117 @@
+package input
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected []Key
+	}{
+		{"printable", []byte("q"), []Key{'q'}},
+		{"multiple", []byte(" p"), []Key{' ', 'p'}},
+		{"arrow up", []byte("\x1b[A"), []Key{KeyUp}},
+		{"arrow down", []byte("\x1b[B"), []Key{KeyDown}},
+		{"arrow right", []byte("\x1b[C"), []Key{KeyRight}},
+		{"arrow left", []byte("\x1b[D"), []Key{KeyLeft}},
+		{"application mode arrow", []byte("\x1bOC"), []Key{KeyRight}},
+		{"arrows and keys", []byte("\x1b[Dx\x1b[C"), []Key{KeyLeft, 'x', KeyRight}},
+		{"lone escape", []byte("\x1b"), []Key{KeyEscape}},
+		{"unknown sequence", []byte("\x1b[Z"), []Key{KeyEscape, '[', 'Z'}},
+		{"empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := ParseKeys(tt.input)
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("ParseKeys(%q) = %v, expected %v", tt.input, keys, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseKeys_Partial(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected []Key
+		rest     string
+	}{
+		{"lone escape", []byte("x\x1b"), []Key{'x'}, "\x1b"},
+		{"escape bracket", []byte("\x1b["), nil, "\x1b["},
+		{"application mode", []byte("\x1bO"), nil, "\x1bO"},
+		{"complete", []byte("\x1b[Cx"), []Key{KeyRight, 'x'}, ""},
+		{"escape and key", []byte("\x1bq"), []Key{KeyEscape, 'q'}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, rest := parseKeys(tt.input, false)
+			if !reflect.DeepEqual(keys, tt.expected) || string(rest) != tt.rest {
+				t.Errorf("parseKeys(%q) = %v, %q, expected %v, %q", tt.input, keys, rest, tt.expected, tt.rest)
+			}
+		})
+	}
+}
+
+// readKey returns the next key from keys, failing after a second.
+func readKey(t *testing.T, keys <-chan Key) Key {
+	t.Helper()
+	select {
+	case key := <-keys:
+		return key
+	case <-time.After(time.Second):
+		t.Fatal("Expected a key press, got none")
+		return 0
+	}
+}
+
+func TestKeyboard_SplitSequence(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	k := &Keyboard{keys: make(chan Key, 16)}
+	go k.read(r)
+
+	// An arrow key split across two reads is still an arrow key
+	if _, err := w.Write([]byte("\x1b")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(escapeTimeout / 4)
+	if _, err := w.Write([]byte("[D")); err != nil {
+		t.Fatal(err)
+	}
+	if key := readKey(t, k.keys); key != KeyLeft {
+		t.Errorf("Expected KeyLeft, got %v", key)
+	}
+
+	// A lone escape is a press of Escape once nothing follows
+	if _, err := w.Write([]byte("\x1b")); err != nil {
+		t.Fatal(err)
+	}
+	if key := readKey(t, k.keys); key != KeyEscape {
+		t.Errorf("Expected KeyEscape, got %v", key)
+	}
+}
+
+func TestNewKeyboard_NotTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := NewKeyboard(f); err == nil {
+		t.Error("Expected error for non-terminal file, got none")
+	}
+}
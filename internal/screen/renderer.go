@@ -0,0 +1,132 @@
+package screen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// Renderer draws a stream of buffers on the terminal. After the first frame
+// it only redraws the cells that changed, jumping the cursor over the others,
+// so the output grows with the motion in the picture rather than with the
+// size of the screen.
+//
+// Renderer is not safe for concurrent use.
+type Renderer struct {
+	threshold float64
+	full      bool    // redraw every frame in full
+	shown     *Buffer // what the screen shows, nil if unknown
+}
+
+// NewRenderer returns a renderer that leaves cells alone whose glyph stays
+// the same and whose colors change by no more than threshold: the distance
+// in CIELAB space, where about 0.02 is barely noticeable. A threshold of 0
+// redraws every change.
+func NewRenderer(threshold float64) *Renderer {
+	return &Renderer{threshold: threshold}
+}
+
+// NewFullRenderer returns a renderer that clears the screen and redraws every
+// frame in full.
+func NewFullRenderer() *Renderer {
+	return &Renderer{full: true}
+}
+
+// Invalidate makes the next frame redraw the whole screen, for when
+// something else was drawn on it.
+func (r *Renderer) Invalidate() {
+	r.shown = nil
+}
+
+// Overwritten makes the next frame redraw row y, for when something else was
+// drawn over it.
+func (r *Renderer) Overwritten(y int) {
+	if r.shown == nil || y < 0 || y >= r.shown.height {
+		return
+	}
+	for x := 0; x < r.shown.width; x++ {
+		r.shown.Set(x, y, Cell{Glyph: -1}) // No frame has this glyph
+	}
+}
+
+// Render returns the text that updates the screen to show b. The first
+// frame, and any after Invalidate or a change of size, clears the screen and
+// draws all of b.
+func (r *Renderer) Render(b *Buffer) string {
+	if r.full || r.shown == nil || r.shown.width != b.width || r.shown.height != b.height {
+		r.shown = &Buffer{width: b.width, height: b.height, cells: append([]Cell(nil), b.cells...)}
+		return redraw(b)
+	}
+
+	str := strings.Builder{}
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; {
+			if !r.changed(x, y, b) {
+				x++
+				continue
+			}
+
+			// Draw the run of changed cells starting here, in groups of the
+			// same colors
+			fmt.Fprintf(&str, "\033[%d;%dH", y+1, x+1)
+			for x < b.width && r.changed(x, y, b) {
+				first := b.At(x, y)
+				glyphs := strings.Builder{}
+				for ; x < b.width && r.changed(x, y, b); x++ {
+					c := b.At(x, y)
+					if c.FG != first.FG || c.BG != first.BG {
+						break
+					}
+					glyphs.WriteRune(c.Glyph)
+					r.shown.Set(x, y, c)
+				}
+				str.WriteString(first.style(glyphs.String()).String())
+			}
+		}
+	}
+	return str.String()
+}
+
+// redraw returns the text that clears the screen and draws all of b. Unlike
+// b.String it ends without a newline, which would scroll a frame as tall as
+// the screen up by a row.
+func redraw(b *Buffer) string {
+	str := strings.Builder{}
+	str.WriteString("\033[H\033[J")
+	for y := 0; y < b.height; y++ {
+		if y > 0 {
+			str.WriteString("\n")
+		}
+		b.writeRow(&str, y)
+	}
+	return str.String()
+}
+
+// changed reports whether cell x, y of b looks different from the screen.
+func (r *Renderer) changed(x, y int, b *Buffer) bool {
+	shown, c := r.shown.At(x, y), b.At(x, y)
+	return shown.Glyph != c.Glyph || !r.similar(shown.FG, c.FG) || !r.similar(shown.BG, c.BG)
+}
+
+// similar reports whether colors a and b are within the threshold of each
+// other.
+func (r *Renderer) similar(a, b termenv.Color) bool {
+	if a == b {
+		return true
+	}
+	if r.threshold <= 0 || !isColor(a) || !isColor(b) {
+		return false
+	}
+	return termenv.ConvertToRGB(a).DistanceLab(termenv.ConvertToRGB(b)) <= r.threshold
+}
+
+// isColor reports whether c is an actual color rather than the terminal
+// default.
+func isColor(c termenv.Color) bool {
+	switch c.(type) {
+	case termenv.RGBColor, termenv.ANSIColor, termenv.ANSI256Color:
+		return true
+	}
+	return false
+}
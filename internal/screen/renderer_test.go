@@ -0,0 +1,141 @@
+package screen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+// filled returns a width x height buffer of glyph in color fg.
+func filled(width, height int, glyph rune, fg termenv.Color) *Buffer {
+	b := NewBuffer(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			b.Set(x, y, Cell{Glyph: glyph, FG: fg})
+		}
+	}
+	return b
+}
+
+func TestRenderer_FirstFrame(t *testing.T) {
+	r := NewRenderer(0)
+	b := filled(3, 2, '#', nil)
+
+	want := "\033[H\033[J" + strings.TrimSuffix(b.String(), "\n")
+	if got := r.Render(b); got != want {
+		t.Errorf("Expected the whole frame, got %q", got)
+	}
+
+	// An unchanged frame needs no output
+	if got := r.Render(filled(3, 2, '#', nil)); got != "" {
+		t.Errorf("Expected no output for an unchanged frame, got %q", got)
+	}
+}
+
+func TestRenderer_FullHeight(t *testing.T) {
+	// A newline after the last row would scroll a frame as tall as the
+	// screen, leaving every later change one row off
+	got := NewRenderer(0).Render(filled(3, 4, '#', nil))
+	if strings.HasSuffix(got, "\n") || strings.Count(got, "\n") != 3 {
+		t.Errorf("Expected newlines between the rows only, got %q", got)
+	}
+}
+
+func TestRenderer_ChangedCells(t *testing.T) {
+	r := NewRenderer(0)
+	r.Render(filled(6, 3, '.', nil))
+
+	b := filled(6, 3, '.', nil)
+	b.Set(1, 0, Cell{Glyph: 'a'})
+	b.Set(2, 0, Cell{Glyph: 'b'})
+	b.Set(4, 2, Cell{Glyph: 'c', FG: termenv.ANSIColor(2)})
+
+	// Runs of changed cells are drawn after jumping to them
+	want := "\033[1;2Hab\033[3;5H\x1b[32mc\x1b[0m"
+	if got := r.Render(b); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := r.Render(b); got != "" {
+		t.Errorf("Expected no output for the same frame again, got %q", got)
+	}
+}
+
+func TestRenderer_ColorGroups(t *testing.T) {
+	r := NewRenderer(0)
+	r.Render(filled(4, 1, ' ', nil))
+
+	red, blue := termenv.ANSIColor(1), termenv.ANSIColor(4)
+	b := NewBuffer(4, 1)
+	b.Set(0, 0, Cell{Glyph: 'a', FG: red})
+	b.Set(1, 0, Cell{Glyph: 'b', FG: red})
+	b.Set(2, 0, Cell{Glyph: 'c', FG: blue})
+	b.Set(3, 0, Cell{Glyph: 'd', FG: blue})
+
+	// Neighbors of the same color share their escape sequence
+	want := "\033[1;1H\x1b[31mab\x1b[0m\x1b[34mcd\x1b[0m"
+	if got := r.Render(b); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRenderer_Threshold(t *testing.T) {
+	r := NewRenderer(0.02)
+	r.Render(filled(4, 2, '#', termenv.RGBColor("#808080")))
+
+	// Barely different grays are left alone, as long as they stay close to
+	// the color the screen shows rather than to the previous frame
+	for _, c := range []string{"#818181", "#828282", "#838383", "#848484", "#858585"} {
+		if got := r.Render(filled(4, 2, '#', termenv.RGBColor(c))); got != "" {
+			t.Errorf("Expected no output for %s, got %q", c, got)
+		}
+	}
+	if got := r.Render(filled(4, 2, '#', termenv.RGBColor("#868686"))); strings.Count(got, "#") != 4*2 {
+		t.Errorf("Expected the drifted color to be redrawn, got %q", got)
+	}
+
+	// Without a threshold every change is drawn
+	r = NewRenderer(0)
+	r.Render(filled(4, 2, '#', termenv.RGBColor("#808080")))
+	if got := r.Render(filled(4, 2, '#', termenv.RGBColor("#818181"))); got == "" {
+		t.Error("Expected a changed color to be redrawn without threshold")
+	}
+}
+
+func TestRenderer_Redraw(t *testing.T) {
+	r := NewRenderer(0)
+	r.Render(filled(4, 2, '#', nil))
+
+	// A new size redraws everything
+	b := filled(5, 2, '#', nil)
+	if got := r.Render(b); !strings.HasPrefix(got, "\033[H\033[J") {
+		t.Errorf("Expected a full redraw after resizing, got %q", got)
+	}
+
+	r.Invalidate()
+	if got := r.Render(b); got != "\033[H\033[J"+strings.TrimSuffix(b.String(), "\n") {
+		t.Errorf("Expected a full redraw after Invalidate, got %q", got)
+	}
+
+	full := NewFullRenderer()
+	full.Render(b)
+	if got := full.Render(b); got != "\033[H\033[J"+strings.TrimSuffix(b.String(), "\n") {
+		t.Errorf("Expected a full redraw of every frame, got %q", got)
+	}
+}
+
+func TestRenderer_Overwritten(t *testing.T) {
+	r := NewRenderer(0)
+	b := filled(3, 2, '#', nil)
+	r.Render(b)
+
+	// Only the overwritten row is drawn again
+	r.Overwritten(1)
+	r.Overwritten(5)
+	if got, want := r.Render(b), "\033[2;1H###"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := r.Render(b); got != "" {
+		t.Errorf("Expected no output once the row is redrawn, got %q", got)
+	}
+}
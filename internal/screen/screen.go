@@ -0,0 +1,70 @@
+// Package screen models the terminal as a grid of cells, so a frame can be
+// drawn by updating only the cells that changed since the previous one.
+package screen
+
+import (
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// Cell is a single character position on the screen.
+type Cell struct {
+	Glyph rune
+	FG    termenv.Color // foreground color, nil for the terminal default
+	BG    termenv.Color // background color, nil for the terminal default
+}
+
+// style returns the style drawing text in the colors of the cell.
+func (c Cell) style(s string) termenv.Style {
+	return termenv.String(s).Foreground(c.FG).Background(c.BG)
+}
+
+// Buffer is a frame of width x height cells.
+type Buffer struct {
+	width, height int
+	cells         []Cell
+}
+
+// NewBuffer returns a buffer of width x height blank cells.
+func NewBuffer(width, height int) *Buffer {
+	width, height = max(width, 0), max(height, 0)
+	cells := make([]Cell, width*height)
+	for i := range cells {
+		cells[i].Glyph = ' '
+	}
+	return &Buffer{width: width, height: height, cells: cells}
+}
+
+// Size returns the width and height of the buffer in cells.
+func (b *Buffer) Size() (int, int) {
+	return b.width, b.height
+}
+
+// At returns the cell at x, y.
+func (b *Buffer) At(x, y int) Cell {
+	return b.cells[y*b.width+x]
+}
+
+// Set changes the cell at x, y.
+func (b *Buffer) Set(x, y int, c Cell) {
+	b.cells[y*b.width+x] = c
+}
+
+// String returns the buffer as text to print at the top left of the screen:
+// every cell in its colors, with each row ending in a newline.
+func (b *Buffer) String() string {
+	str := strings.Builder{}
+	for y := 0; y < b.height; y++ {
+		b.writeRow(&str, y)
+		str.WriteString("\n") // End of row
+	}
+	return str.String()
+}
+
+// writeRow writes every cell of row y to str in its colors.
+func (b *Buffer) writeRow(str *strings.Builder, y int) {
+	for _, c := range b.cells[y*b.width : (y+1)*b.width] {
+		str.WriteString(c.style(string(c.Glyph)).String())
+	}
+}
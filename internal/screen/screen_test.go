@@ -0,0 +1,42 @@
+package screen
+
+import (
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestBuffer_String(t *testing.T) {
+	b := NewBuffer(2, 2)
+	b.Set(0, 0, Cell{Glyph: 'a'})
+	b.Set(1, 0, Cell{Glyph: '▀', FG: termenv.ANSIColor(1), BG: termenv.ANSIColor(4)})
+
+	// Blank cells are spaces, rows end in newlines
+	want := "a\x1b[31;44m▀\x1b[0m\n  \n"
+	if got := b.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestBuffer_SetAt(t *testing.T) {
+	b := NewBuffer(3, 2)
+	if w, h := b.Size(); w != 3 || h != 2 {
+		t.Fatalf("Expected 3x2 buffer, got %dx%d", w, h)
+	}
+
+	c := Cell{Glyph: '#', FG: termenv.RGBColor("#ff0000")}
+	b.Set(2, 1, c)
+	if got := b.At(2, 1); got != c {
+		t.Errorf("Expected %v, got %v", c, got)
+	}
+	if got := b.At(1, 1); got.Glyph != ' ' {
+		t.Errorf("Expected a blank cell, got %q", got.Glyph)
+	}
+}
+
+func TestNewBuffer_Empty(t *testing.T) {
+	b := NewBuffer(0, 2)
+	if got := b.String(); got != "\n\n" {
+		t.Errorf("Expected two empty rows, got %q", got)
+	}
+}